@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ResettableBackoff produces exponentially-growing delays (doubling from `base`
+// up to `max`) for reconnect loops, and can be reset back to `base` once a
+// connection has proven healthy.  It's safe for concurrent use.
+type ResettableBackoff struct {
+	base  time.Duration
+	max   time.Duration
+	clock Clock
+
+	mu   sync.Mutex
+	next time.Duration
+}
+
+func NewResettableBackoff(base, max time.Duration) *ResettableBackoff {
+	return &ResettableBackoff{base: base, max: max, next: base, clock: RealClock{}}
+}
+
+// WithClock sets the clock used by Wait, for testing.
+func (b *ResettableBackoff) WithClock(clock Clock) *ResettableBackoff {
+	b.clock = clock
+	return b
+}
+
+// NextDelay returns the current delay and grows the delay for the next call.
+func (b *ResettableBackoff) NextDelay() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delay := b.next
+	b.next *= 2
+	if b.next > b.max || b.next <= 0 {
+		b.next = b.max
+	}
+	return delay
+}
+
+// Reset returns the delay to `base`.
+func (b *ResettableBackoff) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.next = b.base
+}
+
+// Wait sleeps for NextDelay(), returning early with the context's error if
+// `ctx` is done first.
+func (b *ResettableBackoff) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.clock.After(b.NextDelay()):
+		return nil
+	}
+}
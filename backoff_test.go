@@ -0,0 +1,48 @@
+package utils_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestResettableBackoff(t *testing.T) {
+	t.Run("grows, caps, and resets", func(t *testing.T) {
+		b := utils.NewResettableBackoff(100*time.Millisecond, time.Second)
+
+		var delays []time.Duration
+		for i := 0; i < 6; i++ {
+			delays = append(delays, b.NextDelay())
+		}
+		require.Equal(t, []time.Duration{
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			time.Second,
+			time.Second,
+		}, delays)
+
+		b.Reset()
+		require.Equal(t, 100*time.Millisecond, b.NextDelay())
+	})
+
+	t.Run("Wait uses the clock and respects the context", func(t *testing.T) {
+		clock := utils.NewFakeClock(time.Now())
+		b := utils.NewResettableBackoff(time.Second, time.Minute).WithClock(clock)
+
+		chErr := make(chan error)
+		go func() { chErr <- b.Wait(context.Background()) }()
+		require.Eventually(t, func() bool { return clock.Waiters() == 1 }, time.Second, time.Millisecond)
+		clock.Advance(time.Second)
+		require.NoError(t, <-chErr)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, b.Wait(ctx), context.Canceled)
+	})
+}
@@ -2,24 +2,262 @@ package utils
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 func CollectChan[T any](ctx context.Context, n int, ch <-chan T) []T {
-	items := make([]T, n)
-	i := 0
-	for i := 0; i < n; i++ {
+	items := make([]T, 0, n)
+	for len(items) < n {
 		select {
 		case item, open := <-ch:
 			if !open {
-				break
+				return items
 			}
-			items[i] = item
+			items = append(items, item)
 		case <-ctx.Done():
-			break
+			return items
 		}
 	}
-	return items[:i]
+	return items
+}
+
+// CollectChanTimeout is like CollectChan, but also returns whatever has been
+// collected once `timeout` has elapsed since the call began.  A timeout of 0
+// means no timeout.
+func CollectChanTimeout[T any](ctx context.Context, n int, timeout time.Duration, ch <-chan T) []T {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return CollectChan(ctx, n, ch)
+}
+
+// BatchChan groups the items received from `in` into batches, emitting a batch
+// whenever `maxSize` items have accumulated or `maxWait` has elapsed since the
+// first item of the batch arrived.  When `in` closes, any partial batch is
+// flushed before the output channel is closed.  If `ctx` is cancelled, the
+// output channel is closed without flushing.
+func BatchChan[T any](ctx context.Context, in <-chan T, maxSize int, maxWait time.Duration) <-chan []T {
+	chOut := make(chan []T)
+	go func() {
+		defer close(chOut)
+
+		var batch []T
+		var timer *time.Timer
+		var chTimer <-chan time.Time
+
+		flush := func() bool {
+			if timer != nil {
+				timer.Stop()
+				timer, chTimer = nil, nil
+			}
+			if len(batch) == 0 {
+				return true
+			}
+			select {
+			case chOut <- batch:
+				batch = nil
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-chTimer:
+				timer, chTimer = nil, nil
+				if !flush() {
+					return
+				}
+
+			case item, open := <-in:
+				if !open {
+					flush()
+					return
+				}
+				batch = append(batch, item)
+				if len(batch) == 1 && maxWait > 0 {
+					timer = time.NewTimer(maxWait)
+					chTimer = timer.C
+				}
+				if len(batch) >= maxSize {
+					if !flush() {
+						return
+					}
+				}
+			}
+		}
+	}()
+	return chOut
+}
+
+// DebounceChan emits once on the returned channel after `in` has been quiet for
+// `interval` following one or more signals, so that a burst of signals produces
+// a single emission.  If the consumer hasn't received the previous emission
+// yet, the new one is coalesced into it.  When `in` closes or `ctx` is
+// cancelled, the output channel is closed, and any pending emission is
+// dropped.
+func DebounceChan(ctx context.Context, interval time.Duration, in <-chan struct{}) <-chan struct{} {
+	chOut := make(chan struct{}, 1)
+	go func() {
+		defer close(chOut)
+
+		var timer *time.Timer
+		var chTimer <-chan time.Time
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-chTimer:
+				timer, chTimer = nil, nil
+				select {
+				case chOut <- struct{}{}:
+				default:
+				}
+
+			case _, open := <-in:
+				if !open {
+					return
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.NewTimer(interval)
+				chTimer = timer.C
+			}
+		}
+	}()
+	return chOut
+}
+
+// ThrottleChan forwards items from `in` to the returned channel, ensuring at
+// least `minInterval` between emissions.  Items that arrive while the interval
+// is still running are coalesced: only the most recent one is kept, and it's
+// emitted once the interval elapses.  Items that arrive less often than
+// `minInterval` pass straight through with no added delay.  When `in` closes,
+// any pending item is emitted (after its interval) before the output channel
+// is closed.  If `ctx` is cancelled, the output channel is closed immediately.
+func ThrottleChan[T any](ctx context.Context, minInterval time.Duration, in <-chan T) <-chan T {
+	chOut := make(chan T)
+	go func() {
+		defer close(chOut)
+
+		var (
+			lastEmit   time.Time
+			pending    T
+			hasPending bool
+			timer      *time.Timer
+			chTimer    <-chan time.Time
+		)
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+
+		emit := func(item T) bool {
+			select {
+			case chOut <- item:
+				lastEmit = time.Now()
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-chTimer:
+				timer, chTimer = nil, nil
+				if hasPending {
+					var zero T
+					item := pending
+					pending, hasPending = zero, false
+					if !emit(item) {
+						return
+					}
+				}
+				if in == nil {
+					return
+				}
+
+			case item, open := <-in:
+				if !open {
+					if !hasPending {
+						return
+					}
+					in = nil
+					continue
+				}
+
+				if timer == nil && time.Since(lastEmit) >= minInterval {
+					if !emit(item) {
+						return
+					}
+					continue
+				}
+				pending, hasPending = item, true
+				if timer == nil {
+					timer = time.NewTimer(minInterval - time.Since(lastEmit))
+					chTimer = timer.C
+				}
+			}
+		}
+	}()
+	return chOut
+}
+
+// FanIn merges the items received from `chans` into the returned channel,
+// which closes once every input has closed or `ctx` is done.  Items from a
+// single input keep their relative order; there's no ordering between inputs.
+func FanIn[T any](ctx context.Context, chans ...<-chan T) <-chan T {
+	chOut := make(chan T)
+
+	var wg sync.WaitGroup
+	wg.Add(len(chans))
+	for _, ch := range chans {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case item, open := <-ch:
+					if !open {
+						return
+					}
+					select {
+					case chOut <- item:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(chOut)
+	}()
+	return chOut
 }
 
 // WaitGroupChan creates a channel that closes when the provided sync.WaitGroup is done.
@@ -0,0 +1,274 @@
+package utils_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestCollectChan(t *testing.T) {
+	t.Run("collects n items", func(t *testing.T) {
+		ch := make(chan int, 5)
+		for i := 0; i < 5; i++ {
+			ch <- i
+		}
+		require.Equal(t, []int{0, 1, 2}, utils.CollectChan(context.Background(), 3, ch))
+		require.Equal(t, []int{3, 4}, utils.CollectChan(context.Background(), 2, ch))
+	})
+
+	t.Run("stops when the channel closes", func(t *testing.T) {
+		ch := make(chan int, 2)
+		ch <- 1
+		ch <- 2
+		close(ch)
+		require.Equal(t, []int{1, 2}, utils.CollectChan(context.Background(), 5, ch))
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		ch := make(chan int, 1)
+		ch <- 1
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.Equal(t, []int{1}, utils.CollectChan(ctx, 5, ch))
+	})
+}
+
+func TestCollectChanTimeout(t *testing.T) {
+	t.Run("slow producer yields a partial batch", func(t *testing.T) {
+		ch := make(chan int)
+		go func() {
+			for i := 0; i < 10; i++ {
+				select {
+				case ch <- i:
+				case <-time.After(time.Second):
+					return
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+		}()
+
+		start := time.Now()
+		items := utils.CollectChanTimeout(context.Background(), 10, 50*time.Millisecond, ch)
+		elapsed := time.Since(start)
+		require.NotEmpty(t, items)
+		require.Less(t, len(items), 10)
+		require.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+		require.Less(t, elapsed, 500*time.Millisecond)
+	})
+
+	t.Run("returns early once n items arrive", func(t *testing.T) {
+		ch := make(chan int, 3)
+		ch <- 1
+		ch <- 2
+		ch <- 3
+		require.Equal(t, []int{1, 2}, utils.CollectChanTimeout(context.Background(), 2, time.Hour, ch))
+	})
+
+	t.Run("zero means no timeout", func(t *testing.T) {
+		ch := make(chan int)
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			ch <- 1
+		}()
+		require.Equal(t, []int{1}, utils.CollectChanTimeout(context.Background(), 1, 0, ch))
+	})
+}
+
+func TestBatchChan(t *testing.T) {
+	t.Run("size-triggered batches", func(t *testing.T) {
+		in := make(chan int)
+		out := utils.BatchChan(context.Background(), in, 3, time.Hour)
+
+		go func() {
+			for i := 0; i < 6; i++ {
+				in <- i
+			}
+		}()
+		require.Equal(t, []int{0, 1, 2}, <-out)
+		require.Equal(t, []int{3, 4, 5}, <-out)
+		close(in)
+		_, open := <-out
+		require.False(t, open)
+	})
+
+	t.Run("time-triggered batches", func(t *testing.T) {
+		in := make(chan int)
+		out := utils.BatchChan(context.Background(), in, 100, 20*time.Millisecond)
+		defer close(in)
+
+		start := time.Now()
+		in <- 1
+		in <- 2
+		require.Equal(t, []int{1, 2}, <-out)
+		require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+
+	t.Run("final partial batch is flushed on close", func(t *testing.T) {
+		in := make(chan int, 5)
+		out := utils.BatchChan(context.Background(), in, 3, time.Hour)
+
+		for i := 0; i < 5; i++ {
+			in <- i
+		}
+		close(in)
+
+		var batches [][]int
+		for batch := range out {
+			batches = append(batches, batch)
+		}
+		require.Equal(t, [][]int{{0, 1, 2}, {3, 4}}, batches)
+	})
+}
+
+func TestDebounceChan(t *testing.T) {
+	t.Run("a burst produces one emission", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		in := make(chan struct{})
+		out := utils.DebounceChan(ctx, 30*time.Millisecond, in)
+
+		for i := 0; i < 10; i++ {
+			in <- struct{}{}
+			time.Sleep(time.Millisecond)
+		}
+
+		select {
+		case <-out:
+		case <-time.After(time.Second):
+			t.Fatal("expected an emission")
+		}
+
+		select {
+		case <-out:
+			t.Fatal("expected exactly one emission")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("closing the input closes the output", func(t *testing.T) {
+		in := make(chan struct{})
+		out := utils.DebounceChan(context.Background(), time.Hour, in)
+		in <- struct{}{}
+		close(in)
+
+		_, open := <-out
+		require.False(t, open)
+	})
+
+	t.Run("cancelling the context closes the output", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		out := utils.DebounceChan(ctx, time.Hour, make(chan struct{}))
+		cancel()
+
+		_, open := <-out
+		require.False(t, open)
+	})
+}
+
+func TestThrottleChan(t *testing.T) {
+	t.Run("coalesces bursts", func(t *testing.T) {
+		in := make(chan int)
+		out := utils.ThrottleChan(context.Background(), 50*time.Millisecond, in)
+
+		go func() {
+			defer close(in)
+			for i := 0; i < 10; i++ {
+				in <- i
+			}
+		}()
+
+		var got []int
+		var times []time.Time
+		for x := range out {
+			got = append(got, x)
+			times = append(times, time.Now())
+		}
+		require.Equal(t, []int{0, 9}, got)
+		require.GreaterOrEqual(t, times[1].Sub(times[0]), 45*time.Millisecond)
+	})
+
+	t.Run("slow items pass through without delay", func(t *testing.T) {
+		in := make(chan int)
+		out := utils.ThrottleChan(context.Background(), 10*time.Millisecond, in)
+
+		for i := 0; i < 3; i++ {
+			time.Sleep(20 * time.Millisecond)
+			sent := time.Now()
+			in <- i
+			require.Equal(t, i, <-out)
+			require.Less(t, time.Since(sent), 10*time.Millisecond)
+		}
+		close(in)
+		_, open := <-out
+		require.False(t, open)
+	})
+
+	t.Run("cancelling the context closes the output", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		out := utils.ThrottleChan(ctx, time.Hour, make(chan int))
+		cancel()
+		_, open := <-out
+		require.False(t, open)
+	})
+}
+
+func TestFanIn(t *testing.T) {
+	t.Run("merges inputs that close at different times", func(t *testing.T) {
+		a, b, c := make(chan int), make(chan int), make(chan int)
+		out := utils.FanIn(context.Background(), a, b, c)
+
+		go func() {
+			defer close(a)
+			a <- 1
+		}()
+		go func() {
+			defer close(b)
+			for i := 10; i < 13; i++ {
+				time.Sleep(5 * time.Millisecond)
+				b <- i
+			}
+		}()
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			c <- 100
+			close(c)
+		}()
+
+		var got []int
+		for x := range out {
+			got = append(got, x)
+		}
+		require.ElementsMatch(t, []int{1, 10, 11, 12, 100}, got)
+	})
+
+	t.Run("no inputs", func(t *testing.T) {
+		_, open := <-utils.FanIn[int](context.Background())
+		require.False(t, open)
+	})
+
+	t.Run("cancellation doesn't leak goroutines", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		for i := 0; i < 10; i++ {
+			ctx, cancel := context.WithCancel(context.Background())
+			a, b := make(chan int), make(chan int, 1)
+			b <- 1 // never received
+			out := utils.FanIn(ctx, a, b)
+			cancel()
+			for range out {
+			}
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		require.LessOrEqual(t, runtime.NumGoroutine(), before)
+	})
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+// CheckedIDAlphabet is the set of characters allowed in the payload of a
+// checked id (Crockford's base32).  Payloads are case-insensitive.
+const CheckedIDAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewCheckedID appends a check character to `payload`, which must be a
+// non-empty string of characters from CheckedIDAlphabet.
+// The check character is computed with the Damm algorithm over GF(32), which
+// detects every single-character substitution and every transposition of
+// adjacent characters.
+func NewCheckedID(payload string) (string, error) {
+	if payload == "" {
+		return "", errors.New("checked id payload is empty")
+	}
+	payload = strings.ToUpper(payload)
+	interim, err := dammInterim(payload)
+	if err != nil {
+		return "", err
+	}
+	// The check character c is the one for which interim∘c == 0
+	return payload + string(CheckedIDAlphabet[gf32Mul(dammMultiplier, interim)]), nil
+}
+
+// ValidateCheckedID verifies the check character of an id created with
+// NewCheckedID, returning the (uppercased) payload if it's valid.
+func ValidateCheckedID(id string) (payload string, ok bool) {
+	if len(id) < 2 {
+		return "", false
+	}
+	id = strings.ToUpper(id)
+	interim, err := dammInterim(id)
+	if err != nil || interim != 0 {
+		return "", false
+	}
+	return id[:len(id)-1], true
+}
+
+// dammMultiplier is the constant `a` in the quasigroup operation x∘y = a·x ⊕ y
+// over GF(32).  Any `a` other than 0 and 1 makes the operation totally
+// anti-symmetric, which is what lets the Damm algorithm catch transpositions.
+const dammMultiplier = 2
+
+func dammInterim(s string) (byte, error) {
+	var interim byte
+	for i := 0; i < len(s); i++ {
+		idx := strings.IndexByte(CheckedIDAlphabet, s[i])
+		if idx < 0 {
+			return 0, errors.Errorf("invalid character %q in checked id", s[i])
+		}
+		interim = gf32Mul(dammMultiplier, interim) ^ byte(idx)
+	}
+	return interim, nil
+}
+
+// gf32Mul multiplies two elements of GF(32) (reduced by x^5 + x^2 + 1).
+func gf32Mul(a, b byte) byte {
+	var product byte
+	for b > 0 {
+		if b&1 != 0 {
+			product ^= a
+		}
+		b >>= 1
+		a <<= 1
+		if a&0x20 != 0 {
+			a ^= 0x25
+		}
+	}
+	return product
+}
@@ -0,0 +1,54 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestCheckedID(t *testing.T) {
+	t.Run("valid id", func(t *testing.T) {
+		id, err := utils.NewCheckedID("7KD2X9")
+		require.NoError(t, err)
+		require.Len(t, id, 7)
+
+		payload, ok := utils.ValidateCheckedID(id)
+		require.True(t, ok)
+		require.Equal(t, "7KD2X9", payload)
+
+		_, err = utils.NewCheckedID("not-valid!")
+		require.Error(t, err)
+
+		_, err = utils.NewCheckedID("")
+		require.Error(t, err)
+	})
+
+	id, err := utils.NewCheckedID("ABC123XYZ")
+	require.NoError(t, err)
+
+	t.Run("every single-character typo is caught", func(t *testing.T) {
+		for i := 0; i < len(id); i++ {
+			for _, c := range utils.CheckedIDAlphabet {
+				if byte(c) == id[i] {
+					continue
+				}
+				typo := id[:i] + string(c) + id[i+1:]
+				_, ok := utils.ValidateCheckedID(typo)
+				require.False(t, ok, "typo %v not caught", typo)
+			}
+		}
+	})
+
+	t.Run("every adjacent transposition is caught", func(t *testing.T) {
+		for i := 0; i < len(id)-1; i++ {
+			if id[i] == id[i+1] {
+				continue
+			}
+			swapped := id[:i] + string(id[i+1]) + string(id[i]) + id[i+2:]
+			_, ok := utils.ValidateCheckedID(swapped)
+			require.False(t, ok, "transposition %v not caught", swapped)
+		}
+	})
+}
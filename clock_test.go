@@ -0,0 +1,68 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("After fires once the clock has advanced far enough", func(t *testing.T) {
+		clock := utils.NewFakeClock(start)
+		ch := clock.After(10 * time.Second)
+
+		clock.Advance(9 * time.Second)
+		select {
+		case <-ch:
+			t.Fatal("fired early")
+		default:
+		}
+
+		clock.Advance(time.Second)
+		require.Equal(t, start.Add(10*time.Second), <-ch)
+		require.Equal(t, 0, clock.Waiters())
+	})
+
+	t.Run("tickers fire every period until stopped", func(t *testing.T) {
+		clock := utils.NewFakeClock(start)
+		ticker := clock.NewTicker(time.Minute)
+
+		for i := 1; i <= 3; i++ {
+			clock.Advance(time.Minute)
+			require.Equal(t, start.Add(time.Duration(i)*time.Minute), <-ticker.C())
+		}
+
+		ticker.Stop()
+		clock.Advance(time.Minute)
+		select {
+		case <-ticker.C():
+			t.Fatal("stopped ticker fired")
+		default:
+		}
+	})
+
+	t.Run("Sleep drives delays without real waiting", func(t *testing.T) {
+		clock := utils.NewFakeClock(start)
+		delays := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for _, d := range delays {
+				clock.Sleep(d)
+			}
+		}()
+
+		for _, d := range delays {
+			require.Eventually(t, func() bool { return clock.Waiters() == 1 }, time.Second, time.Millisecond)
+			clock.Advance(d)
+		}
+		<-done
+		require.Equal(t, start.Add(7*time.Second), clock.Now())
+	})
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// BitSet is a compact set of non-negative integers, best suited to dense ids.
+// Its backing storage grows lazily as larger values are added.  The zero value
+// is an empty set.
+type BitSet struct {
+	words []uint64
+}
+
+func NewBitSet() *BitSet {
+	return &BitSet{}
+}
+
+func (b *BitSet) Set(i uint) {
+	word := i / 64
+	if word >= uint(len(b.words)) {
+		words := make([]uint64, word+1)
+		copy(words, b.words)
+		b.words = words
+	}
+	b.words[word] |= 1 << (i % 64)
+}
+
+func (b *BitSet) Clear(i uint) {
+	word := i / 64
+	if word < uint(len(b.words)) {
+		b.words[word] &^= 1 << (i % 64)
+	}
+}
+
+func (b *BitSet) Has(i uint) bool {
+	word := i / 64
+	return word < uint(len(b.words)) && b.words[word]&(1<<(i%64)) != 0
+}
+
+// Count returns the number of elements in the set.
+func (b *BitSet) Count() int {
+	var n int
+	for _, w := range b.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// And returns a new set containing the elements present in both sets.
+func (b *BitSet) And(other *BitSet) *BitSet {
+	n := min(len(b.words), len(other.words))
+	out := &BitSet{words: make([]uint64, n)}
+	for i := 0; i < n; i++ {
+		out.words[i] = b.words[i] & other.words[i]
+	}
+	return out
+}
+
+// Or returns a new set containing the elements present in either set.
+func (b *BitSet) Or(other *BitSet) *BitSet {
+	long, short := b.words, other.words
+	if len(short) > len(long) {
+		long, short = short, long
+	}
+	out := &BitSet{words: make([]uint64, len(long))}
+	copy(out.words, long)
+	for i := range short {
+		out.words[i] |= short[i]
+	}
+	return out
+}
+
+// AndNot returns a new set containing the elements of `b` that are not in
+// `other`.
+func (b *BitSet) AndNot(other *BitSet) *BitSet {
+	out := &BitSet{words: make([]uint64, len(b.words))}
+	copy(out.words, b.words)
+	for i := 0; i < min(len(out.words), len(other.words)); i++ {
+		out.words[i] &^= other.words[i]
+	}
+	return out
+}
+
+// Iter iterates over the elements of the set in ascending order.
+func (b *BitSet) Iter() iter.Seq[uint] {
+	return func(yield func(uint) bool) {
+		for i, w := range b.words {
+			for w != 0 {
+				bit := uint(bits.TrailingZeros64(w))
+				if !yield(uint(i)*64 + bit) {
+					return
+				}
+				w &= w - 1
+			}
+		}
+	}
+}
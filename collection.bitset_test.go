@@ -0,0 +1,71 @@
+package utils_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func bitSetOf(xs ...uint) *utils.BitSet {
+	b := utils.NewBitSet()
+	for _, x := range xs {
+		b.Set(x)
+	}
+	return b
+}
+
+func TestBitSet(t *testing.T) {
+	t.Run("set, clear, has", func(t *testing.T) {
+		var b utils.BitSet
+		require.False(t, b.Has(0))
+		require.False(t, b.Has(1000))
+
+		b.Set(0)
+		b.Set(63)
+		b.Set(64)
+		b.Set(1000)
+		require.True(t, b.Has(0))
+		require.True(t, b.Has(63))
+		require.True(t, b.Has(64))
+		require.True(t, b.Has(1000))
+		require.False(t, b.Has(1))
+		require.False(t, b.Has(999))
+		require.Equal(t, 4, b.Count())
+
+		b.Clear(63)
+		b.Clear(5000) // out of range is a no-op
+		require.False(t, b.Has(63))
+		require.Equal(t, 3, b.Count())
+	})
+
+	t.Run("boolean operations", func(t *testing.T) {
+		a := bitSetOf(1, 2, 3, 100, 200)
+		b := bitSetOf(2, 3, 4, 200)
+
+		require.Equal(t, []uint{2, 3, 200}, slices.Collect(a.And(b).Iter()))
+		require.Equal(t, []uint{1, 2, 3, 4, 100, 200}, slices.Collect(a.Or(b).Iter()))
+		require.Equal(t, []uint{1, 100}, slices.Collect(a.AndNot(b).Iter()))
+		require.Equal(t, []uint{4}, slices.Collect(b.AndNot(a).Iter()))
+
+		// Operands are not mutated
+		require.Equal(t, 5, a.Count())
+		require.Equal(t, 4, b.Count())
+	})
+
+	t.Run("iteration is ascending", func(t *testing.T) {
+		b := bitSetOf(500, 3, 64, 0, 129)
+		require.Equal(t, []uint{0, 3, 64, 129, 500}, slices.Collect(b.Iter()))
+
+		var first []uint
+		for x := range b.Iter() {
+			first = append(first, x)
+			if len(first) == 2 {
+				break
+			}
+		}
+		require.Equal(t, []uint{0, 3}, first)
+	})
+}
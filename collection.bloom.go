@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+)
+
+// BloomFilter is a probabilistic set membership test.  MightContain never
+// returns false for an item that was added, but may return true for one that
+// wasn't, at roughly the false-positive rate the filter was sized for.
+type BloomFilter struct {
+	bits      *BitSet
+	numBits   uint64
+	numHashes uint64
+}
+
+// NewBloomFilter creates a filter sized to hold `expectedItems` items with
+// approximately the given false-positive rate.
+func NewBloomFilter(expectedItems int, falsePositiveRate float64) *BloomFilter {
+	if expectedItems < 1 {
+		expectedItems = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+
+	n := float64(expectedItems)
+	numBits := math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	numHashes := math.Max(1, math.Round(numBits/n*math.Ln2))
+
+	return &BloomFilter{
+		bits:      NewBitSet(),
+		numBits:   uint64(numBits),
+		numHashes: uint64(numHashes),
+	}
+}
+
+func (f *BloomFilter) Add(item []byte) {
+	h1, h2 := bloomHashes(item)
+	for i := uint64(0); i < f.numHashes; i++ {
+		f.bits.Set(uint((h1 + i*h2) % f.numBits))
+	}
+}
+
+func (f *BloomFilter) MightContain(item []byte) bool {
+	h1, h2 := bloomHashes(item)
+	for i := uint64(0); i < f.numHashes; i++ {
+		if !f.bits.Has(uint((h1 + i*h2) % f.numBits)) {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHashes derives the two hashes used for double hashing (h1 + i*h2) from
+// the two halves of a single 128-bit FNV-1a hash.
+func bloomHashes(item []byte) (uint64, uint64) {
+	h := fnv.New128a()
+	h.Write(item)
+	sum := h.Sum(nil)
+	return binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:]) | 1
+}
@@ -0,0 +1,38 @@
+package utils_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestBloomFilter(t *testing.T) {
+	const (
+		n      = 10000
+		target = 0.01
+	)
+	f := utils.NewBloomFilter(n, target)
+
+	for i := 0; i < n; i++ {
+		f.Add([]byte(fmt.Sprintf("notification-%d", i)))
+	}
+
+	// No false negatives
+	for i := 0; i < n; i++ {
+		require.True(t, f.MightContain([]byte(fmt.Sprintf("notification-%d", i))))
+	}
+
+	// False-positive rate near the target
+	var falsePositives int
+	const trials = 100000
+	for i := 0; i < trials; i++ {
+		if f.MightContain([]byte(fmt.Sprintf("other-%d", i))) {
+			falsePositives++
+		}
+	}
+	rate := float64(falsePositives) / trials
+	require.Less(t, rate, target*2, "false-positive rate %v", rate)
+}
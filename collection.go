@@ -34,3 +34,65 @@ func (m Set[T]) Remove(item T) bool {
 	delete(m, item)
 	return has
 }
+
+// Union returns a new set containing the elements of both `m` and `other`.
+func (m Set[T]) Union(other Set[T]) Set[T] {
+	out := make(Set[T], len(m)+len(other))
+	out.AddSet(m)
+	out.AddSet(other)
+	return out
+}
+
+// Intersection returns a new set containing the elements present in both `m`
+// and `other`.
+func (m Set[T]) Intersection(other Set[T]) Set[T] {
+	out := NewSet[T]()
+	for item := range m {
+		if other.Has(item) {
+			out.Add(item)
+		}
+	}
+	return out
+}
+
+// Difference returns a new set containing the elements of `m` that are not in
+// `other`.
+func (m Set[T]) Difference(other Set[T]) Set[T] {
+	out := NewSet[T]()
+	for item := range m {
+		if !other.Has(item) {
+			out.Add(item)
+		}
+	}
+	return out
+}
+
+// SymmetricDifference returns a new set containing the elements that are in
+// exactly one of `m` and `other`.
+func (m Set[T]) SymmetricDifference(other Set[T]) Set[T] {
+	out := m.Difference(other)
+	for item := range other {
+		if !m.Has(item) {
+			out.Add(item)
+		}
+	}
+	return out
+}
+
+// IsSubsetOf reports whether every element of `m` is also in `other`.
+func (m Set[T]) IsSubsetOf(other Set[T]) bool {
+	if len(m) > len(other) {
+		return false
+	}
+	for item := range m {
+		if !other.Has(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether `m` and `other` contain the same elements.
+func (m Set[T]) Equal(other Set[T]) bool {
+	return len(m) == len(other) && m.IsSubsetOf(other)
+}
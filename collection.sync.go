@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"iter"
 	"sync"
 )
 
@@ -79,3 +80,42 @@ func (m *SyncSet[T]) Remove(item T) bool {
 	defer m.mu.Unlock()
 	return m.m.Remove(item)
 }
+
+func (m *SyncSet[T]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.m)
+}
+
+// Slice returns a snapshot of the set's elements in unspecified order.
+func (m *SyncSet[T]) Slice() []T {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	items := make([]T, 0, len(m.m))
+	for item := range m.m {
+		items = append(items, item)
+	}
+	return items
+}
+
+// Iter iterates over a snapshot of the set taken when iteration begins, so the
+// set may be safely modified during iteration.
+func (m *SyncSet[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, item := range m.Slice() {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// ForEach calls `fn` for each element of a snapshot of the set, stopping early
+// if `fn` returns false.
+func (m *SyncSet[T]) ForEach(fn func(T) bool) {
+	for item := range m.Iter() {
+		if !fn(item) {
+			return
+		}
+	}
+}
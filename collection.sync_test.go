@@ -0,0 +1,53 @@
+package utils_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestSyncSet_Snapshot(t *testing.T) {
+	s := utils.NewSyncSet[int]()
+	for i := 0; i < 100; i++ {
+		s.Add(i)
+	}
+	require.Equal(t, 100, s.Len())
+	require.Len(t, s.Slice(), 100)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 100; i < 1000; i++ {
+			s.Add(i)
+			s.Remove(i - 100)
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		seen := make(map[int]struct{})
+		for x := range s.Iter() {
+			_, dup := seen[x]
+			require.False(t, dup)
+			seen[x] = struct{}{}
+		}
+		// The writer keeps the set's size between 99 and 101 elements
+		require.GreaterOrEqual(t, len(seen), 99)
+		require.LessOrEqual(t, len(seen), 101)
+	}
+	wg.Wait()
+
+	require.Equal(t, 100, s.Len())
+
+	var count int
+	s.ForEach(func(x int) bool {
+		s.Remove(x) // mutating during ForEach doesn't deadlock
+		count++
+		return count < 10
+	})
+	require.Equal(t, 10, count)
+	require.Equal(t, 90, s.Len())
+}
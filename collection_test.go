@@ -0,0 +1,52 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func setOf[T comparable](items ...T) utils.Set[T] {
+	s := utils.NewSet[T]()
+	s.AddAll(items...)
+	return s
+}
+
+func TestSetAlgebra(t *testing.T) {
+	a := setOf(1, 2, 3, 4)
+	b := setOf(3, 4, 5)
+
+	require.Equal(t, setOf(1, 2, 3, 4, 5), a.Union(b))
+	require.Equal(t, setOf(3, 4), a.Intersection(b))
+	require.Equal(t, setOf(1, 2), a.Difference(b))
+	require.Equal(t, setOf(5), b.Difference(a))
+	require.Equal(t, setOf(1, 2, 5), a.SymmetricDifference(b))
+	require.Equal(t, a.SymmetricDifference(b), b.SymmetricDifference(a))
+
+	// Operands are not mutated
+	require.Equal(t, setOf(1, 2, 3, 4), a)
+	require.Equal(t, setOf(3, 4, 5), b)
+
+	t.Run("subsets", func(t *testing.T) {
+		require.True(t, setOf(1, 2).IsSubsetOf(a))
+		require.True(t, a.IsSubsetOf(a))
+		require.True(t, utils.NewSet[int]().IsSubsetOf(a))
+		require.False(t, a.IsSubsetOf(setOf(1, 2)))
+		require.False(t, b.IsSubsetOf(a))
+	})
+
+	t.Run("disjoint sets", func(t *testing.T) {
+		c := setOf(10, 11)
+		require.Empty(t, a.Intersection(c))
+		require.Equal(t, a, a.Difference(c))
+		require.Equal(t, a.Union(c), a.SymmetricDifference(c))
+	})
+
+	t.Run("equality", func(t *testing.T) {
+		require.True(t, a.Equal(setOf(4, 3, 2, 1)))
+		require.False(t, a.Equal(b))
+		require.False(t, a.Equal(setOf(1, 2, 3)))
+	})
+}
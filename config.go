@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"encoding"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+var ErrMissingEnv = errors.New("missing required environment variable")
+
+// LoadConfig fills a T from environment variables.  Each exported field is read
+// from `{prefix}_{NAME}`, where NAME is the field's `env` tag, or else its name
+// in UPPER_SNAKE_CASE (so `MaxConns` reads `{prefix}_MAX_CONNS`).  If `prefix`
+// is empty, the variable is just NAME.
+//
+// If a variable is unset but `{name}_FILE` is set, the trimmed contents of the
+// file it names are used instead (the convention for secrets mounted into
+// containers); the variable itself takes precedence when both are set.
+// Unset variables fall back to the field's `default` tag, if any; fields tagged
+// `required:"true"` with neither are reported as missing.  Nested struct fields
+// are loaded recursively with their own NAME appended to the prefix, while
+// embedded structs share their parent's prefix.  Values are parsed like
+// UnmarshalHTTPRequest parses query parameters, with durations accepting
+// time.ParseDuration syntax and slices split on commas; pointer and slice
+// elements are parsed the same way.  Unsupported field types are reported as
+// errors.
+//
+// All problems are reported together: the returned error joins one error per
+// missing variable (each wrapping ErrMissingEnv), unreadable _FILE, and
+// unparseable value.
+func LoadConfig[T any](prefix string) (T, error) {
+	var cfg T
+	val := reflect.ValueOf(&cfg).Elem()
+	if val.Kind() != reflect.Struct {
+		return cfg, errors.Errorf("LoadConfig: %T is not a struct", cfg)
+	}
+
+	var errs errors.Accumulator
+	loadConfigStruct(val, prefix, &errs)
+	return cfg, errs.Err()
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func loadConfigStruct(val reflect.Value, prefix string, errs *errors.Accumulator) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldVal := val.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			loadConfigStruct(fieldVal, prefix, errs)
+			continue
+		} else if !field.IsExported() {
+			continue
+		}
+
+		name := field.Tag.Get("env")
+		if name == "-" {
+			continue
+		} else if name == "" {
+			name = toUpperSnakeCase(field.Name)
+		}
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		if field.Type.Kind() == reflect.Struct && !reflect.PointerTo(field.Type).Implements(textUnmarshalerType) {
+			loadConfigStruct(fieldVal, name, errs)
+			continue
+		}
+
+		value, exists, err := lookupConfigEnv(name)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		if !exists {
+			value, exists = field.Tag.Lookup("default")
+		}
+		if !exists {
+			if field.Tag.Get("required") == "true" {
+				errs.Add(errors.Wrap(ErrMissingEnv, name))
+			}
+			continue
+		}
+
+		err = setConfigField(fieldVal, value)
+		if err != nil {
+			errs.Add(errors.Wrapf(err, "invalid value for %v", name))
+		}
+	}
+}
+
+// lookupConfigEnv returns the value of the environment variable `name`, or if
+// it's unset, the trimmed contents of the file named by `{name}_FILE`.
+func lookupConfigEnv(name string) (string, bool, error) {
+	if value, exists := os.LookupEnv(name); exists {
+		return value, true, nil
+	}
+	path, exists := os.LookupEnv(name + "_FILE")
+	if !exists {
+		return "", false, nil
+	}
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "reading %v_FILE", name)
+	}
+	return strings.TrimSpace(string(bs)), true, nil
+}
+
+// setConfigField parses `value` into `fieldVal`.  Pointers are allocated and
+// slices are split on commas, with each element parsed the same way, so that
+// durations and floats are handled at any depth.
+func setConfigField(fieldVal reflect.Value, value string) error {
+	typ := fieldVal.Type()
+	if reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		return fieldVal.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	} else if typ == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		fieldVal.SetInt(int64(d))
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(typ.Elem())
+		err := setConfigField(elem.Elem(), value)
+		if err != nil {
+			return err
+		}
+		fieldVal.Set(elem)
+		return nil
+
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return unmarshalHTTPField("", value, nil, fieldVal.Addr())
+		}
+		slice := reflect.MakeSlice(typ, 0, 0)
+		for i, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v == "" {
+				continue
+			}
+			elem := reflect.New(typ.Elem()).Elem()
+			err := setConfigField(elem, v)
+			if err != nil {
+				return errors.Wrapf(err, "element %d", i)
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		fieldVal.Set(slice)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, typ.Bits())
+		if err != nil {
+			return err
+		}
+		fieldVal.SetFloat(f)
+		return nil
+
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return unmarshalHTTPField("", value, nil, fieldVal.Addr())
+
+	default:
+		return errors.Errorf("unsupported config field type %v", typ)
+	}
+}
+
+// toUpperSnakeCase converts a Go identifier like "APIKey" or "MaxConns" to
+// "API_KEY" or "MAX_CONNS".
+func toUpperSnakeCase(s string) string {
+	runes := []rune(s)
+	var sb strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
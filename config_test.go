@@ -0,0 +1,172 @@
+package utils_test
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+type testDBConfig struct {
+	URL      string `required:"true"`
+	MaxConns int    `default:"10"`
+}
+
+type testCommonConfig struct {
+	LogLevel string `default:"info"`
+}
+
+type testConfig struct {
+	testCommonConfig
+	Name     string `env:"APP_NAME" required:"true"`
+	Port     int    `default:"8080"`
+	Debug    bool
+	Timeout  time.Duration `default:"5s"`
+	Ratio    float64
+	Hosts    []string
+	Addr     netip.Addr
+	APIKey   string
+	DB       testDBConfig
+	Replica  testDBConfig `env:"RO"`
+	internal string
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("prefixes, nesting, and defaults", func(t *testing.T) {
+		t.Setenv("SVC_APP_NAME", "widgets")
+		t.Setenv("SVC_DEBUG", "true")
+		t.Setenv("SVC_RATIO", "0.25")
+		t.Setenv("SVC_HOSTS", "a.example, b.example")
+		t.Setenv("SVC_ADDR", "10.0.0.1")
+		t.Setenv("SVC_API_KEY", "k")
+		t.Setenv("SVC_LOG_LEVEL", "debug")
+		t.Setenv("SVC_DB_URL", "postgres://primary")
+		t.Setenv("SVC_DB_MAX_CONNS", "50")
+		t.Setenv("SVC_RO_URL", "postgres://replica")
+		t.Setenv("APP_NAME", "unprefixed")
+
+		cfg, err := utils.LoadConfig[testConfig]("SVC")
+		require.NoError(t, err)
+		require.Equal(t, testConfig{
+			testCommonConfig: testCommonConfig{LogLevel: "debug"},
+			Name:             "widgets",
+			Port:             8080,
+			Debug:            true,
+			Timeout:          5 * time.Second,
+			Ratio:            0.25,
+			Hosts:            []string{"a.example", "b.example"},
+			Addr:             netip.MustParseAddr("10.0.0.1"),
+			APIKey:           "k",
+			DB:               testDBConfig{URL: "postgres://primary", MaxConns: 50},
+			Replica:          testDBConfig{URL: "postgres://replica", MaxConns: 10},
+		}, cfg)
+	})
+
+	t.Run("missing required vars are aggregated", func(t *testing.T) {
+		cfg, err := utils.LoadConfig[testConfig]("EMPTY")
+		require.ErrorIs(t, err, utils.ErrMissingEnv)
+		for _, name := range []string{"EMPTY_APP_NAME", "EMPTY_DB_URL", "EMPTY_RO_URL"} {
+			require.Contains(t, err.Error(), name)
+		}
+		require.Equal(t, 8080, cfg.Port)
+		require.Equal(t, "info", cfg.LogLevel)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		t.Setenv("BAD_APP_NAME", "x")
+		t.Setenv("BAD_DB_URL", "x")
+		t.Setenv("BAD_RO_URL", "x")
+		t.Setenv("BAD_PORT", "eighty")
+		t.Setenv("BAD_TIMEOUT", "soon")
+
+		_, err := utils.LoadConfig[testConfig]("BAD")
+		require.Error(t, err)
+		require.False(t, errors.Is(err, utils.ErrMissingEnv))
+		require.Contains(t, err.Error(), "BAD_PORT")
+		require.Contains(t, err.Error(), "BAD_TIMEOUT")
+	})
+
+	t.Run("no prefix", func(t *testing.T) {
+		type simple struct {
+			HomeDir string `env:"TEST_LOADCONFIG_HOME"`
+		}
+		t.Setenv("TEST_LOADCONFIG_HOME", filepath.Join(os.TempDir(), "home"))
+
+		cfg, err := utils.LoadConfig[simple]("")
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(os.TempDir(), "home"), cfg.HomeDir)
+	})
+
+	t.Run("_FILE fallback", func(t *testing.T) {
+		dir := t.TempDir()
+		secretPath := filepath.Join(dir, "db_url")
+		require.NoError(t, os.WriteFile(secretPath, []byte("postgres://from-file\n"), 0600))
+
+		t.Setenv("FILE_APP_NAME", "x")
+		t.Setenv("FILE_RO_URL", "postgres://replica")
+		t.Setenv("FILE_DB_URL_FILE", secretPath)
+
+		cfg, err := utils.LoadConfig[testConfig]("FILE")
+		require.NoError(t, err)
+		require.Equal(t, "postgres://from-file", cfg.DB.URL)
+
+		t.Run("the variable itself takes precedence", func(t *testing.T) {
+			t.Setenv("FILE_DB_URL", "postgres://from-env")
+			cfg, err := utils.LoadConfig[testConfig]("FILE")
+			require.NoError(t, err)
+			require.Equal(t, "postgres://from-env", cfg.DB.URL)
+		})
+
+		t.Run("missing file", func(t *testing.T) {
+			t.Setenv("FILE_DB_URL_FILE", filepath.Join(dir, "nope"))
+			_, err := utils.LoadConfig[testConfig]("FILE")
+			require.ErrorIs(t, err, os.ErrNotExist)
+			require.Contains(t, err.Error(), "FILE_DB_URL_FILE")
+		})
+	})
+	t.Run("pointers and slices of durations and floats", func(t *testing.T) {
+		type tuning struct {
+			Rate      *float64
+			Weights   []float64
+			Grace     *time.Duration
+			Intervals []time.Duration
+			Unset     *float64
+		}
+		t.Setenv("TUNE_RATE", "0.5")
+		t.Setenv("TUNE_WEIGHTS", "1.5, 2, -0.25")
+		t.Setenv("TUNE_GRACE", "5s")
+		t.Setenv("TUNE_INTERVALS", "1s,250ms, 1m")
+
+		cfg, err := utils.LoadConfig[tuning]("TUNE")
+		require.NoError(t, err)
+		require.Equal(t, tuning{
+			Rate:      utils.PtrTo(0.5),
+			Weights:   []float64{1.5, 2, -0.25},
+			Grace:     utils.PtrTo(5 * time.Second),
+			Intervals: []time.Duration{time.Second, 250 * time.Millisecond, time.Minute},
+		}, cfg)
+
+		t.Setenv("TUNE_INTERVALS", "1s,soon")
+		_, err = utils.LoadConfig[tuning]("TUNE")
+		require.ErrorContains(t, err, "TUNE_INTERVALS")
+	})
+
+	t.Run("unsupported types are an error, not a panic", func(t *testing.T) {
+		type unsupported struct {
+			Lookup map[string]string
+			Point  *complex128
+		}
+		t.Setenv("ODD_LOOKUP", "a=b")
+		t.Setenv("ODD_POINT", "1+2i")
+
+		_, err := utils.LoadConfig[unsupported]("ODD")
+		require.ErrorContains(t, err, "ODD_LOOKUP")
+		require.ErrorContains(t, err, "ODD_POINT")
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"reflect"
 	"time"
+
+	"github.com/brynbellomy/go-utils/errors"
 )
 
 // ContextFromChan creates a context that finishes when the provided channel
@@ -45,41 +47,76 @@ func (ch ChanContext) Value(key interface{}) interface{} {
 	return nil
 }
 
+// contextWithTimeout is context.WithTimeout, swappable so that tests can
+// verify that CombinedContext releases its timeouts.
+var contextWithTimeout = context.WithTimeout
+
+// ErrChannelClosed is the cause (see context.Cause) of a CombinedContext that
+// finished because one of its channel signals received or was closed.
+var ErrChannelClosed = errors.New("signal channel closed")
+
 // CombinedContext creates a context that finishes when any of the provided
 // signals finish.  A signal can be a `context.Context`, a `chan struct{}`, or
 // a `time.Duration` (which is transformed into a `context.WithTimeout`).
+//
+// context.Cause of the combined context reports which signal finished it: the
+// cause of a context signal, ErrChannelClosed for a channel signal, or
+// context.DeadlineExceeded for a duration signal.
 func CombinedContext(signals ...interface{}) (context.Context, context.CancelFunc) {
-	ctx, cancel := context.WithCancel(context.Background())
+	return CombinedContextWithParent(context.Background(), signals...)
+}
+
+// CombinedContextWithParent is like CombinedContext, but the combined context
+// (and any timeouts created from `time.Duration` signals) are children of
+// `parent`, inheriting its deadline, values, and cancellation.
+func CombinedContextWithParent(parent context.Context, signals ...interface{}) (context.Context, context.CancelFunc) {
+	ctx, cancelCause := context.WithCancelCause(parent)
+	cancel := func() { cancelCause(nil) }
 	if len(signals) == 0 {
 		return ctx, cancel
 	}
-	signals = append(signals, ctx)
 
 	var cases []reflect.SelectCase
+	var causes []func() error
+	var otherCancels []context.CancelFunc
 	for _, signal := range signals {
 		var ch reflect.Value
+		var cause func() error
 
 		switch sig := signal.(type) {
 		case context.Context:
 			ch = reflect.ValueOf(sig.Done())
+			cause = func() error { return context.Cause(sig) }
 		case <-chan struct{}:
 			ch = reflect.ValueOf(sig)
+			cause = func() error { return ErrChannelClosed }
 		case chan struct{}:
 			ch = reflect.ValueOf(sig)
+			cause = func() error { return ErrChannelClosed }
 		case time.Duration:
-			var ctxTimeout context.Context
-			ctxTimeout, _ = context.WithTimeout(ctx, sig)
+			ctxTimeout, cancelTimeout := contextWithTimeout(ctx, sig)
+			otherCancels = append(otherCancels, cancelTimeout)
 			ch = reflect.ValueOf(ctxTimeout.Done())
+			cause = func() error { return context.Cause(ctxTimeout) }
 		default:
 			continue
 		}
 		cases = append(cases, reflect.SelectCase{Chan: ch, Dir: reflect.SelectRecv})
+		causes = append(causes, cause)
 	}
+	// The combined context itself finishes if the parent does or if `cancel`
+	// is called, in which case its cause is already set
 	cases = append(cases, reflect.SelectCase{Chan: reflect.ValueOf(ctx.Done()), Dir: reflect.SelectRecv})
+	causes = append(causes, func() error { return nil })
 
 	go func() {
-		defer cancel()
-		_, _, _ = reflect.Select(cases)
+		defer func() {
+			for _, cancelOther := range otherCancels {
+				cancelOther()
+			}
+		}()
+		chosen, _, _ := reflect.Select(cases)
+		cancelCause(causes[chosen]())
 	}()
 
 	return ctx, cancel
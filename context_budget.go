@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// DeadlineBudget splits the time remaining before a context's deadline across
+// a series of sequential steps.  See Budget.
+type DeadlineBudget struct {
+	ctx   context.Context
+	clock Clock
+}
+
+// Budget returns a DeadlineBudget for `ctx`.  Each call to Next carves a
+// sub-deadline out of whatever time remains, so steps that finish early leave
+// more time for the ones that follow.
+func Budget(ctx context.Context) *DeadlineBudget {
+	return &DeadlineBudget{ctx: ctx, clock: RealClock{}}
+}
+
+// WithClock sets the clock used to compute remaining time, for testing.
+func (b *DeadlineBudget) WithClock(clock Clock) *DeadlineBudget {
+	b.clock = clock
+	return b
+}
+
+// Remaining returns the time left before the parent context's deadline (never
+// negative).  If the parent has no deadline, it returns the maximum Duration.
+func (b *DeadlineBudget) Remaining() time.Duration {
+	deadline, ok := b.ctx.Deadline()
+	if !ok {
+		return time.Duration(math.MaxInt64)
+	}
+	return max(deadline.Sub(b.clock.Now()), 0)
+}
+
+// Next returns a child context whose deadline is `fraction` (clamped to
+// [0, 1]) of the remaining budget from now.  If the parent has no deadline,
+// the child has none either.
+func (b *DeadlineBudget) Next(fraction float64) (context.Context, context.CancelFunc) {
+	deadline, ok := b.ctx.Deadline()
+	if !ok {
+		return context.WithCancel(b.ctx)
+	}
+	fraction = min(max(fraction, 0), 1)
+
+	now := b.clock.Now()
+	remaining := max(deadline.Sub(now), 0)
+	return context.WithDeadline(b.ctx, now.Add(time.Duration(float64(remaining)*fraction)))
+}
@@ -0,0 +1,71 @@
+package utils_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestBudget(t *testing.T) {
+	t.Run("sub-deadlines stay within the parent budget", func(t *testing.T) {
+		start := time.Now()
+		clock := utils.NewFakeClock(start)
+		parentDeadline := start.Add(time.Hour)
+		parent, cancel := context.WithDeadline(context.Background(), parentDeadline)
+		defer cancel()
+
+		budget := utils.Budget(parent).WithClock(clock)
+		require.Equal(t, time.Hour, budget.Remaining())
+
+		ctx1, cancel1 := budget.Next(0.5)
+		defer cancel1()
+		deadline1, ok := ctx1.Deadline()
+		require.True(t, ok)
+		require.Equal(t, start.Add(30*time.Minute), deadline1)
+
+		// The first step finishes early, leaving 50 minutes
+		clock.Advance(10 * time.Minute)
+		require.Equal(t, 50*time.Minute, budget.Remaining())
+
+		ctx2, cancel2 := budget.Next(0.5)
+		defer cancel2()
+		deadline2, _ := ctx2.Deadline()
+		require.Equal(t, clock.Now().Add(25*time.Minute), deadline2)
+
+		ctx3, cancel3 := budget.Next(2)
+		defer cancel3()
+		deadline3, _ := ctx3.Deadline()
+		require.Equal(t, parentDeadline, deadline3)
+
+		for _, d := range []time.Time{deadline1, deadline2, deadline3} {
+			require.False(t, d.After(parentDeadline))
+		}
+	})
+
+	t.Run("no deadline", func(t *testing.T) {
+		budget := utils.Budget(context.Background())
+		require.Equal(t, time.Duration(math.MaxInt64), budget.Remaining())
+
+		ctx, cancel := budget.Next(0.5)
+		_, ok := ctx.Deadline()
+		require.False(t, ok)
+		cancel()
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+
+	t.Run("exhausted budget", func(t *testing.T) {
+		start := time.Now()
+		clock := utils.NewFakeClock(start)
+		parent, cancel := context.WithDeadline(context.Background(), start.Add(time.Minute))
+		defer cancel()
+
+		budget := utils.Budget(parent).WithClock(clock)
+		clock.Advance(2 * time.Minute)
+		require.Equal(t, time.Duration(0), budget.Remaining())
+	})
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCombinedContext_CancelsEveryTimeout(t *testing.T) {
+	var created, cancelled atomic.Int64
+	contextWithTimeout = func(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+		created.Add(1)
+		ctx, cancel := context.WithTimeout(parent, d)
+		return ctx, func() {
+			cancelled.Add(1)
+			cancel()
+		}
+	}
+	defer func() { contextWithTimeout = context.WithTimeout }()
+
+	const n = 200
+	for i := 0; i < n; i++ {
+		ctx, cancel := CombinedContext(make(chan struct{}), time.Millisecond, time.Hour)
+		<-ctx.Done()
+		cancel()
+	}
+
+	require.Equal(t, int64(2*n), created.Load())
+	require.Eventually(t, func() bool { return cancelled.Load() == 2*n }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,109 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+type ctxKey struct{}
+
+func TestCombinedContextWithParent(t *testing.T) {
+	t.Run("parent values are visible", func(t *testing.T) {
+		parent := context.WithValue(context.Background(), ctxKey{}, "hello")
+		ctx, cancel := utils.CombinedContextWithParent(parent, make(chan struct{}), time.Hour)
+		defer cancel()
+
+		require.Equal(t, "hello", ctx.Value(ctxKey{}))
+	})
+
+	t.Run("parent cancellation propagates", func(t *testing.T) {
+		parent, cancelParent := context.WithCancel(context.Background())
+		ctx, cancel := utils.CombinedContextWithParent(parent, make(chan struct{}), time.Hour)
+		defer cancel()
+
+		cancelParent()
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("combined context was not cancelled with its parent")
+		}
+	})
+
+	t.Run("parent deadline is inherited", func(t *testing.T) {
+		parent, cancelParent := context.WithTimeout(context.Background(), time.Minute)
+		defer cancelParent()
+		ctx, cancel := utils.CombinedContextWithParent(parent, time.Hour)
+		defer cancel()
+
+		parentDeadline, _ := parent.Deadline()
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		require.Equal(t, parentDeadline, deadline)
+	})
+}
+
+func TestCombinedContext_Cause(t *testing.T) {
+	errShutdown := errors.New("shutting down")
+
+	waitDone := func(t *testing.T, ctx context.Context) {
+		t.Helper()
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("combined context did not finish")
+		}
+	}
+
+	t.Run("context signal", func(t *testing.T) {
+		sig, cancelSig := context.WithCancelCause(context.Background())
+		ctx, cancel := utils.CombinedContext(sig, make(chan struct{}), time.Hour)
+		defer cancel()
+
+		cancelSig(errShutdown)
+		waitDone(t, ctx)
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+		require.ErrorIs(t, context.Cause(ctx), errShutdown)
+	})
+
+	t.Run("channel signal", func(t *testing.T) {
+		ch := make(chan struct{})
+		ctx, cancel := utils.CombinedContext(context.Background(), ch, time.Hour)
+		defer cancel()
+
+		close(ch)
+		waitDone(t, ctx)
+		require.ErrorIs(t, context.Cause(ctx), utils.ErrChannelClosed)
+	})
+
+	t.Run("duration signal", func(t *testing.T) {
+		ctx, cancel := utils.CombinedContext(make(chan struct{}), time.Millisecond)
+		defer cancel()
+
+		waitDone(t, ctx)
+		require.ErrorIs(t, context.Cause(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("parent", func(t *testing.T) {
+		parent, cancelParent := context.WithCancelCause(context.Background())
+		ctx, cancel := utils.CombinedContextWithParent(parent, make(chan struct{}))
+		defer cancel()
+
+		cancelParent(errShutdown)
+		waitDone(t, ctx)
+		require.ErrorIs(t, context.Cause(ctx), errShutdown)
+	})
+
+	t.Run("cancel func", func(t *testing.T) {
+		ctx, cancel := utils.CombinedContext(make(chan struct{}), time.Hour)
+		cancel()
+
+		waitDone(t, ctx)
+		require.ErrorIs(t, context.Cause(ctx), context.Canceled)
+	})
+}
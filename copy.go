@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"reflect"
+)
+
+// DeepCopy returns a copy of `v` that shares no mutable memory with it:
+// pointers, slices, maps, arrays, interfaces, and the exported fields of
+// structs are copied recursively.  Pointers of the same type that alias each
+// other in `v` (including cycles) alias the corresponding copies in the result.
+//
+// If `v`, or any value nested inside it, has a `Clone()` method returning its
+// own type, that method is used to copy it instead.  Channels and funcs are
+// copied as-is (the copy refers to the same channel or func), and unexported
+// struct fields are copied shallowly, since reflection can't set them.
+func DeepCopy[T any](v T) T {
+	if c, ok := any(v).(interface{ Clone() T }); ok {
+		return c.Clone()
+	}
+	val := reflect.ValueOf(&v).Elem()
+	out, _ := deepCopyValue(val, make(map[visitedPointer]reflect.Value)).Interface().(T)
+	return out
+}
+
+// visitedPointer identifies a pointer already copied by DeepCopy.  The type is
+// part of the key because a pointer to a struct and a pointer to its first
+// field share an address.
+type visitedPointer struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+func deepCopyValue(val reflect.Value, visited map[visitedPointer]reflect.Value) reflect.Value {
+	if clone, ok := cloneMethod(val); ok {
+		return clone.Call(nil)[0]
+	}
+
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			return val
+		}
+		key := visitedPointer{val.Pointer(), val.Type()}
+		if copied, exists := visited[key]; exists {
+			return copied
+		}
+		out := reflect.New(val.Type().Elem())
+		visited[key] = out
+		out.Elem().Set(deepCopyValue(val.Elem(), visited))
+		return out
+
+	case reflect.Interface:
+		out := reflect.New(val.Type()).Elem()
+		if !val.IsNil() {
+			out.Set(deepCopyValue(val.Elem(), visited))
+		}
+		return out
+
+	case reflect.Struct:
+		out := reflect.New(val.Type()).Elem()
+		out.Set(val)
+		for i := 0; i < val.NumField(); i++ {
+			if val.Type().Field(i).IsExported() {
+				out.Field(i).Set(deepCopyValue(val.Field(i), visited))
+			}
+		}
+		return out
+
+	case reflect.Map:
+		if val.IsNil() {
+			return val
+		}
+		out := reflect.MakeMapWithSize(val.Type(), val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(deepCopyValue(iter.Key(), visited), deepCopyValue(iter.Value(), visited))
+		}
+		return out
+
+	case reflect.Slice:
+		if val.IsNil() {
+			return val
+		}
+		out := reflect.MakeSlice(val.Type(), val.Len(), val.Len())
+		for i := 0; i < val.Len(); i++ {
+			out.Index(i).Set(deepCopyValue(val.Index(i), visited))
+		}
+		return out
+
+	case reflect.Array:
+		out := reflect.New(val.Type()).Elem()
+		for i := 0; i < val.Len(); i++ {
+			out.Index(i).Set(deepCopyValue(val.Index(i), visited))
+		}
+		return out
+
+	default:
+		return val
+	}
+}
+
+// cloneMethod returns `val`'s Clone method if it has one that takes no
+// arguments and returns a value of `val`'s own type.
+func cloneMethod(val reflect.Value) (reflect.Value, bool) {
+	if !val.IsValid() || !val.CanInterface() || (val.Kind() == reflect.Ptr && val.IsNil()) {
+		return reflect.Value{}, false
+	}
+	method := val.MethodByName("Clone")
+	if !method.IsValid() {
+		return reflect.Value{}, false
+	}
+	typ := method.Type()
+	if typ.NumIn() != 0 || typ.NumOut() != 1 || typ.Out(0) != val.Type() {
+		return reflect.Value{}, false
+	}
+	return method, true
+}
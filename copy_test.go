@@ -0,0 +1,105 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+type copyNode struct {
+	Name     string
+	Tags     []string
+	Attrs    map[string][]int
+	Child    *copyNode
+	Parent   *copyNode
+	Any      any
+	Array    [2]*int
+	Callback func() int
+	Ch       chan int
+}
+
+type clonedValue struct {
+	N      int
+	clones *int
+}
+
+func (c clonedValue) Clone() clonedValue {
+	*c.clones++
+	return clonedValue{N: c.N * 10, clones: c.clones}
+}
+
+func TestDeepCopy(t *testing.T) {
+	one := 1
+	ch := make(chan int)
+	original := &copyNode{
+		Name:     "root",
+		Tags:     []string{"a", "b"},
+		Attrs:    map[string][]int{"x": {1, 2}},
+		Child:    &copyNode{Name: "child"},
+		Any:      map[string]any{"nested": []any{"y"}},
+		Array:    [2]*int{&one, nil},
+		Callback: func() int { return 7 },
+		Ch:       ch,
+	}
+	original.Child.Parent = original
+
+	copied := utils.DeepCopy(original)
+	require.NotSame(t, original, copied)
+	require.Equal(t, original.Name, copied.Name)
+	require.Equal(t, original.Tags, copied.Tags)
+	require.Equal(t, original.Attrs, copied.Attrs)
+	require.Equal(t, original.Any, copied.Any)
+	require.Equal(t, 7, copied.Callback())
+	require.Equal(t, ch, copied.Ch)
+
+	// Aliasing (here, a cycle) is preserved
+	require.Same(t, copied, copied.Child.Parent)
+
+	// Mutating the copy leaves the original alone
+	copied.Tags[0] = "changed"
+	copied.Attrs["x"][0] = 100
+	copied.Attrs["new"] = nil
+	copied.Child.Name = "changed"
+	copied.Any.(map[string]any)["nested"].([]any)[0] = "changed"
+	*copied.Array[0] = 100
+
+	require.Equal(t, []string{"a", "b"}, original.Tags)
+	require.Equal(t, map[string][]int{"x": {1, 2}}, original.Attrs)
+	require.Equal(t, "child", original.Child.Name)
+	require.Equal(t, map[string]any{"nested": []any{"y"}}, original.Any)
+	require.Equal(t, 1, one)
+
+	t.Run("uses Clone when present", func(t *testing.T) {
+		var clones int
+		v := clonedValue{N: 1, clones: &clones}
+		require.Equal(t, 10, utils.DeepCopy(v).N)
+		require.Equal(t, 1, clones)
+
+		nested := map[string]clonedValue{"a": v, "b": v}
+		copied := utils.DeepCopy(nested)
+		require.Equal(t, 10, copied["a"].N)
+		require.Equal(t, 3, clones)
+	})
+
+	t.Run("nil and scalar values", func(t *testing.T) {
+		require.Nil(t, utils.DeepCopy[*copyNode](nil))
+		require.Nil(t, utils.DeepCopy[[]int](nil))
+		require.Equal(t, 5, utils.DeepCopy(5))
+		require.Nil(t, utils.DeepCopy[any](nil))
+	})
+	t.Run("pointers to a struct and its first field", func(t *testing.T) {
+		type inner struct{ X, Y int }
+		type outer struct {
+			P *inner
+			Q *int
+		}
+		in := &inner{X: 1, Y: 2}
+		copied := utils.DeepCopy(outer{P: in, Q: &in.X})
+		require.Equal(t, inner{X: 1, Y: 2}, *copied.P)
+		require.Equal(t, 1, *copied.Q)
+		require.NotSame(t, in, copied.P)
+		require.NotSame(t, &in.X, copied.Q)
+	})
+}
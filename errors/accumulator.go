@@ -0,0 +1,54 @@
+package errors
+
+import (
+	stderrors "errors"
+
+	"github.com/pkg/errors"
+)
+
+// Accumulator collects errors (e.g. from validation or batch processing) so
+// that they can be returned together.  The zero value is ready to use.
+type Accumulator struct {
+	errs []error
+}
+
+// Add appends err to the accumulator.  Nil errors are ignored, and the errors
+// held by a nested *Accumulator are flattened into this one.
+func (a *Accumulator) Add(err error) {
+	switch err := err.(type) {
+	case nil:
+	case *Accumulator:
+		if err != nil {
+			a.errs = append(a.errs, err.errs...)
+		}
+	default:
+		a.errs = append(a.errs, err)
+	}
+}
+
+// Addf appends a new error built from the given format string and args.
+func (a *Accumulator) Addf(format string, args ...any) {
+	a.errs = append(a.errs, errors.Errorf(format, args...))
+}
+
+func (a *Accumulator) HasErrors() bool {
+	return len(a.errs) > 0
+}
+
+// Errors returns the accumulated errors in the order they were added.
+func (a *Accumulator) Errors() []error {
+	return a.errs
+}
+
+// Err returns all of the accumulated errors joined with `errors.Join`, or nil
+// if none were added.
+func (a *Accumulator) Err() error {
+	return stderrors.Join(a.errs...)
+}
+
+func (a *Accumulator) Error() string {
+	if err := a.Err(); err != nil {
+		return err.Error()
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package errors_test
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+func TestAccumulator(t *testing.T) {
+	t.Run("empty accumulator returns a nil error", func(t *testing.T) {
+		var acc errors.Accumulator
+		require.False(t, acc.HasErrors())
+		require.NoError(t, acc.Err())
+	})
+
+	t.Run("nil errors are ignored", func(t *testing.T) {
+		var acc errors.Accumulator
+		acc.Add(nil)
+		require.False(t, acc.HasErrors())
+		require.NoError(t, acc.Err())
+	})
+
+	t.Run("accumulates and joins errors", func(t *testing.T) {
+		var acc errors.Accumulator
+		acc.Add(errors.ErrNotFound)
+		acc.Addf("bad field %q", "name")
+		acc.Add(errors.ErrClosed)
+
+		require.True(t, acc.HasErrors())
+		require.Len(t, acc.Errors(), 3)
+
+		err := acc.Err()
+		require.Error(t, err)
+		require.True(t, stderrors.Is(err, errors.ErrNotFound))
+		require.True(t, stderrors.Is(err, errors.ErrClosed))
+		require.Contains(t, err.Error(), `bad field "name"`)
+		require.True(t, errors.IsStatusCoder(err))
+	})
+
+	t.Run("nested accumulators are flattened", func(t *testing.T) {
+		var inner errors.Accumulator
+		inner.Add(errors.ErrBadRequest)
+		inner.Add(errors.ErrForbidden)
+
+		var outer errors.Accumulator
+		outer.Add(errors.ErrClosed)
+		outer.Add(&inner)
+		outer.Add(&errors.Accumulator{})
+
+		require.Equal(t, []error{errors.ErrClosed, errors.ErrBadRequest, errors.ErrForbidden}, outer.Errors())
+	})
+}
@@ -1,22 +1,50 @@
 package errors
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"runtime"
+	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
-// StatusCoder represents an error with an associated HTTP status code
+// StatusCoder represents an error with an associated HTTP status code, plus
+// optional response metadata for WriteStatusCoder.
 type StatusCoder struct {
-	Code    int
-	Message string
+	Code       int
+	Message    string
+	RetryAfter time.Duration
+	Header     http.Header
 }
 
 func (e *StatusCoder) Error() string {
 	return fmt.Sprintf("%d: %s", e.Code, e.Message)
 }
 
+// Temporary reports whether the status code describes a transient condition
+// (timeouts, rate limiting, unavailable upstreams).  This satisfies the
+// `interface{ Temporary() bool }` convention used by the net package.
+func (e *StatusCoder) Temporary() bool {
+	switch e.Code {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Timeout reports whether the status code describes a timeout.
+func (e *StatusCoder) Timeout() bool {
+	return e.Code == http.StatusRequestTimeout || e.Code == http.StatusGatewayTimeout
+}
+
 func NewStatusCoder(code int, message string) *StatusCoder {
 	return &StatusCoder{
 		Code:    code,
@@ -24,6 +52,63 @@ func NewStatusCoder(code int, message string) *StatusCoder {
 	}
 }
 
+// WithRetryAfter returns a copy of `e` that tells clients to retry after `d`.
+func (e *StatusCoder) WithRetryAfter(d time.Duration) *StatusCoder {
+	clone := e.clone()
+	clone.RetryAfter = d
+	return clone
+}
+
+// WithHeader returns a copy of `e` with the response header `key: value`
+// added.
+func (e *StatusCoder) WithHeader(key, value string) *StatusCoder {
+	clone := e.clone()
+	if clone.Header == nil {
+		clone.Header = make(http.Header)
+	}
+	clone.Header.Add(key, value)
+	return clone
+}
+
+func (e *StatusCoder) clone() *StatusCoder {
+	clone := *e
+	clone.Header = e.Header.Clone()
+	return &clone
+}
+
+// WriteStatusCoder writes an HTTP error response for `err` using the nearest
+// StatusCoder in its chain: its headers, a Retry-After header (in whole
+// seconds, rounded up) if RetryAfter is set, its status code, and a JSON body
+// of the form {"error": message}.  Errors without a StatusCoder are written as
+// a generic 500, so that internal details aren't leaked to clients, and a
+// StatusCoder whose code isn't a valid HTTP status (outside 100-599) is written
+// with status 500.
+func WriteStatusCoder(w http.ResponseWriter, err error) {
+	sc, ok := AsStatusCoder(err)
+	if !ok {
+		sc = NewStatusCoder(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+
+	for key, values := range sc.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	if sc.RetryAfter > 0 {
+		seconds := int64((sc.RetryAfter + time.Second - 1) / time.Second)
+		w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	}
+	code := sc.Code
+	if code < 100 || code > 599 {
+		code = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{sc.Message})
+}
+
 func AsStatusCoder(err error) (*StatusCoder, bool) {
 	var httpErr *StatusCoder
 	if errors.As(err, &httpErr) {
@@ -47,6 +132,20 @@ func IsStatusCoder(err error, code ...int) bool {
 	return false
 }
 
+// IsRetryable reports whether any error in err's chain reports itself as
+// temporary or as a timeout (e.g. a 503 `StatusCoder` or a `net.Error`).
+func IsRetryable(err error) bool {
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) && temporary.Temporary() {
+		return true
+	}
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return true
+	}
+	return false
+}
+
 // HTTP-style errors (useful elsewhere too)
 var (
 	ErrBadRequest          = NewStatusCoder(http.StatusBadRequest, "bad Request")
@@ -73,6 +172,8 @@ var (
 	Wrapf     = errors.Wrapf
 	WithStack = errors.WithStack
 	Cause     = errors.Cause
+	As        = errors.As
+	Is        = errors.Is
 )
 
 func Annotate(err *error, msg string, args ...any) {
@@ -87,6 +188,53 @@ func AddStack(err *error) {
 	}
 }
 
+// AddStackSkip is like AddStack, but omits `skip` additional caller frames so
+// that the captured stack starts at the real origin of the error.  This is
+// useful when it's called from a deferred helper.  A skip of 0 records the
+// stack starting at the caller of AddStackSkip.
+func AddStackSkip(err *error, skip int) {
+	if *err != nil {
+		*err = &withStack{*err, callers(skip)}
+	}
+}
+
+type withStack struct {
+	error
+	stack errors.StackTrace
+}
+
+func (w *withStack) Cause() error                  { return w.error }
+func (w *withStack) Unwrap() error                 { return w.error }
+func (w *withStack) StackTrace() errors.StackTrace { return w.stack }
+
+func (w *withStack) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%+v", w.error)
+			w.stack.Format(s, verb)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, w.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.Error())
+	}
+}
+
+func callers(skip int) errors.StackTrace {
+	const depth = 32
+	var pcs [depth]uintptr
+	// Skip runtime.Callers, callers, and AddStackSkip
+	n := runtime.Callers(3+skip, pcs[:])
+	stack := make(errors.StackTrace, n)
+	for i := 0; i < n; i++ {
+		stack[i] = errors.Frame(pcs[i])
+	}
+	return stack
+}
+
 func OneOf(received error, errs ...error) bool {
 	for _, err := range errs {
 		if Cause(received) == err {
@@ -0,0 +1,98 @@
+package errors_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+func TestStatusCoder_Temporary(t *testing.T) {
+	unavailable := errors.NewStatusCoder(http.StatusServiceUnavailable, "down")
+	require.True(t, unavailable.Temporary())
+	require.False(t, unavailable.Timeout())
+	require.True(t, errors.IsRetryable(unavailable))
+	require.True(t, errors.IsRetryable(errors.Wrap(unavailable, "calling upstream")))
+
+	gatewayTimeout := errors.NewStatusCoder(http.StatusGatewayTimeout, "slow")
+	require.True(t, gatewayTimeout.Timeout())
+	require.True(t, errors.IsRetryable(gatewayTimeout))
+
+	badRequest := errors.NewStatusCoder(http.StatusBadRequest, "nope")
+	require.False(t, badRequest.Temporary())
+	require.False(t, badRequest.Timeout())
+	require.False(t, errors.IsRetryable(badRequest))
+
+	require.False(t, errors.IsRetryable(errors.New("plain")))
+	require.False(t, errors.IsRetryable(nil))
+}
+
+func TestAddStackSkip(t *testing.T) {
+	annotate := func(err *error) {
+		errors.AddStackSkip(err, 1)
+	}
+
+	origin := func() (err error) {
+		defer annotate(&err)
+		return errors.New("boom")
+	}
+
+	err := origin()
+	require.Error(t, err)
+	require.Equal(t, "boom", err.Error())
+
+	var tracer interface{ StackTrace() pkgerrors.StackTrace }
+	require.True(t, pkgerrors.As(err, &tracer))
+	stack := tracer.StackTrace()
+	require.NotEmpty(t, stack)
+	require.Contains(t, fmt.Sprintf("%n", stack[0]), "TestAddStackSkip.func2")
+
+	var nilErr error
+	errors.AddStackSkip(&nilErr, 1)
+	require.NoError(t, nilErr)
+}
+
+func TestStatusCoder_ResponseMetadata(t *testing.T) {
+	base := errors.NewStatusCoder(http.StatusTooManyRequests, "slow down")
+	sc := base.WithRetryAfter(1500*time.Millisecond).WithHeader("X-RateLimit-Limit", "100")
+
+	// The builders don't modify the receiver
+	require.Zero(t, base.RetryAfter)
+	require.Nil(t, base.Header)
+
+	rec := httptest.NewRecorder()
+	errors.WriteStatusCoder(rec, errors.Wrap(sc, "handling request"))
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "2", rec.Header().Get("Retry-After"))
+	require.Equal(t, "100", rec.Header().Get("X-RateLimit-Limit"))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"error":"slow down"}`, rec.Body.String())
+
+	t.Run("cloned headers are independent", func(t *testing.T) {
+		other := sc.WithHeader("X-RateLimit-Limit", "200")
+		require.Equal(t, []string{"100"}, sc.Header.Values("X-RateLimit-Limit"))
+		require.Equal(t, []string{"100", "200"}, other.Header.Values("X-RateLimit-Limit"))
+	})
+
+	t.Run("errors without a StatusCoder", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		errors.WriteStatusCoder(rec, errors.New("database password is hunter2"))
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		require.Empty(t, rec.Header().Get("Retry-After"))
+		require.JSONEq(t, `{"error":"Internal Server Error"}`, rec.Body.String())
+	})
+	t.Run("invalid status codes", func(t *testing.T) {
+		for _, code := range []int{0, 42, 600, 1000} {
+			rec := httptest.NewRecorder()
+			errors.WriteStatusCoder(rec, errors.NewStatusCoder(code, "oops"))
+			require.Equal(t, http.StatusInternalServerError, rec.Code)
+			require.JSONEq(t, `{"error":"oops"}`, rec.Body.String())
+		}
+	})
+}
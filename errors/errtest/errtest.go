@@ -0,0 +1,63 @@
+// Package errtest provides test assertions for errors built with the errors
+// package.
+package errtest
+
+import (
+	stderrors "errors"
+
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+// TestingT is the subset of *testing.T used by the assertions in this package.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+	FailNow()
+}
+
+// RequireStatus fails the test unless err carries a StatusCoder with the given
+// code somewhere in its chain.
+func RequireStatus(t TestingT, err error, code int) {
+	t.Helper()
+	sc, ok := errors.AsStatusCoder(err)
+	if !ok {
+		t.Errorf("expected an error with status %d, got %v", code, describe(err))
+		t.FailNow()
+		return
+	}
+	if sc.Code != code {
+		t.Errorf("expected status %d, got %d (%v)", code, sc.Code, err)
+		t.FailNow()
+	}
+}
+
+// RequireRetryable fails the test unless errors.IsRetryable(err) == retryable.
+func RequireRetryable(t TestingT, err error, retryable bool) {
+	t.Helper()
+	if errors.IsRetryable(err) != retryable {
+		if retryable {
+			t.Errorf("expected a retryable error, got %v", describe(err))
+		} else {
+			t.Errorf("expected a non-retryable error, got %v", describe(err))
+		}
+		t.FailNow()
+	}
+}
+
+// RequireCause fails the test unless target is found in err's chain (via
+// errors.Is) or is err's root cause (via errors.Cause).
+func RequireCause(t TestingT, err error, target error) {
+	t.Helper()
+	if stderrors.Is(err, target) || errors.Cause(err) == target {
+		return
+	}
+	t.Errorf("expected %v to have cause %v", describe(err), target)
+	t.FailNow()
+}
+
+func describe(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return err.Error()
+}
@@ -0,0 +1,73 @@
+package errtest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils/errors"
+	"github.com/brynbellomy/go-utils/errors/errtest"
+)
+
+type mockT struct {
+	failed bool
+	msgs   []string
+}
+
+func (m *mockT) Helper() {}
+
+func (m *mockT) Errorf(format string, args ...any) {
+	m.msgs = append(m.msgs, fmt.Sprintf(format, args...))
+}
+
+func (m *mockT) FailNow() {
+	m.failed = true
+}
+
+func TestRequireStatus(t *testing.T) {
+	err := errors.Wrap(errors.ErrNotFound, "loading user")
+
+	m := &mockT{}
+	errtest.RequireStatus(m, err, http.StatusNotFound)
+	require.False(t, m.failed)
+
+	m = &mockT{}
+	errtest.RequireStatus(m, err, http.StatusBadRequest)
+	require.True(t, m.failed)
+	require.Contains(t, m.msgs[0], "expected status 400, got 404")
+
+	m = &mockT{}
+	errtest.RequireStatus(m, errors.New("plain"), http.StatusNotFound)
+	require.True(t, m.failed)
+	require.Contains(t, m.msgs[0], "expected an error with status 404")
+}
+
+func TestRequireRetryable(t *testing.T) {
+	m := &mockT{}
+	errtest.RequireRetryable(m, errors.ErrServiceUnavailable, true)
+	require.False(t, m.failed)
+
+	m = &mockT{}
+	errtest.RequireRetryable(m, errors.ErrBadRequest, false)
+	require.False(t, m.failed)
+
+	m = &mockT{}
+	errtest.RequireRetryable(m, errors.ErrBadRequest, true)
+	require.True(t, m.failed)
+	require.Contains(t, m.msgs[0], "expected a retryable error")
+}
+
+func TestRequireCause(t *testing.T) {
+	err := errors.Wrapf(errors.ErrClosed, "writing %v", "file")
+
+	m := &mockT{}
+	errtest.RequireCause(m, err, errors.ErrClosed)
+	require.False(t, m.failed)
+
+	m = &mockT{}
+	errtest.RequireCause(m, err, errors.ErrConnection)
+	require.True(t, m.failed)
+	require.Contains(t, m.msgs[0], "to have cause connection failed")
+}
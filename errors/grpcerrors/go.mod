@@ -0,0 +1,21 @@
+module github.com/brynbellomy/go-utils/errors/grpcerrors
+
+go 1.25.0
+
+require (
+	github.com/brynbellomy/go-utils v0.0.0
+	github.com/stretchr/testify v1.9.0
+	google.golang.org/grpc v1.84.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/brynbellomy/go-utils => ../..
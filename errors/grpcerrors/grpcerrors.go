@@ -0,0 +1,98 @@
+// Package grpcerrors maps between HTTP status codes (as carried by
+// errors.StatusCoder) and gRPC codes.  It's a separate module so that users of
+// the errors package who don't use gRPC don't pull in its dependency tree.
+package grpcerrors
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+// Status converts a StatusCoder into a gRPC status, so that it can be returned
+// from a gRPC handler as `Status(sc).Err()`.
+func Status(sc *errors.StatusCoder) *status.Status {
+	return status.New(HTTPToGRPCCode(sc.Code), sc.Message)
+}
+
+// FromError returns the gRPC status for the StatusCoder in `err`'s chain, or
+// for codes.Unknown if there isn't one.
+func FromError(err error) *status.Status {
+	if sc, ok := errors.AsStatusCoder(err); ok {
+		return Status(sc)
+	}
+	return status.New(codes.Unknown, err.Error())
+}
+
+// HTTPToGRPCCode maps an HTTP status code to the closest gRPC code.
+func HTTPToGRPCCode(httpCode int) codes.Code {
+	switch httpCode {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499: // nginx's "client closed request"
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusInternalServerError:
+		return codes.Internal
+	}
+	switch {
+	case httpCode >= 200 && httpCode < 300:
+		return codes.OK
+	case httpCode >= 400 && httpCode < 500:
+		return codes.InvalidArgument
+	}
+	return codes.Unknown
+}
+
+// GRPCToHTTPCode maps a gRPC code to the closest HTTP status code.
+func GRPCToHTTPCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
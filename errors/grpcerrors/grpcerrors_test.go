@@ -0,0 +1,45 @@
+package grpcerrors_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/brynbellomy/go-utils/errors"
+
+	"github.com/brynbellomy/go-utils/errors/grpcerrors"
+)
+
+func TestGRPCCodes(t *testing.T) {
+	cases := []struct {
+		http int
+		grpc codes.Code
+	}{
+		{http.StatusNotFound, codes.NotFound},
+		{http.StatusBadRequest, codes.InvalidArgument},
+		{http.StatusServiceUnavailable, codes.Unavailable},
+		{http.StatusUnauthorized, codes.Unauthenticated},
+		{http.StatusTooManyRequests, codes.ResourceExhausted},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.grpc, grpcerrors.HTTPToGRPCCode(c.http))
+		require.Equal(t, c.http, grpcerrors.GRPCToHTTPCode(c.grpc))
+	}
+}
+
+func TestStatus(t *testing.T) {
+	st := grpcerrors.Status(errors.ErrNotFound)
+	require.Equal(t, codes.NotFound, st.Code())
+	require.Equal(t, "not found", st.Message())
+
+	st, ok := status.FromError(grpcerrors.FromError(errors.Wrap(errors.ErrNotFound, "loading widget")).Err())
+	require.True(t, ok)
+	require.Equal(t, codes.NotFound, st.Code())
+
+	st = grpcerrors.FromError(errors.New("boom"))
+	require.Equal(t, codes.Unknown, st.Code())
+	require.Equal(t, "boom", st.Message())
+}
@@ -84,6 +84,98 @@ func JSONRequest(ctx context.Context, method string, url string, body any, heade
 	return resp.Header, resp.StatusCode, nil
 }
 
+// FormRequest sends `values` as an `application/x-www-form-urlencoded` body and
+// decodes the response as JSON into `response`.  If `response` is a `*[]byte`,
+// the raw response body is stored in it instead.
+func FormRequest(ctx context.Context, method string, urlStr string, values url.Values, headers http.Header, response any) (http.Header, int, error) {
+	if headers == nil {
+		headers = http.Header{}
+	}
+
+	headers["Content-Type"] = []string{"application/x-www-form-urlencoded"}
+
+	resp, err := HTTPRequest(ctx, method, urlStr, strings.NewReader(values.Encode()), headers)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if raw, is := response.(*[]byte); is {
+		*raw, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return resp.Header, resp.StatusCode, err
+		}
+		return resp.Header, resp.StatusCode, nil
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return resp.Header, resp.StatusCode, err
+	}
+	return resp.Header, resp.StatusCode, nil
+}
+
+// HTTPDoer is satisfied by *http.Client and *HTTPClient.
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Call sends an HTTP request built from the `query:"..."`, `header:"..."`, and
+// `body:"..."` tagged fields of `req` (see MarshalQuery), and unmarshals the
+// response into a `Resp` using its `header:"..."`, `body:"..."`, and
+// `status:""` tagged fields (see UnmarshalHTTPResponse).  If `client` is nil,
+// http.DefaultClient is used.  Responses with a 4xx or 5xx status are returned
+// as an *errors.StatusCoder whose message is the response body.
+func Call[Req, Resp any](ctx context.Context, client HTTPDoer, method string, urlStr string, req Req) (Resp, error) {
+	var resp Resp
+
+	query, headers, body, err := marshalHTTPRequest(req)
+	if err != nil {
+		return resp, err
+	}
+
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return resp, err
+	}
+	if len(query) > 0 {
+		q := u.Query()
+		for k, vs := range query {
+			q[k] = append(q[k], vs...)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	httpReq.Header = headers
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return resp, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode >= 400 {
+		msg, _ := io.ReadAll(httpResp.Body)
+		if len(msg) == 0 {
+			msg = []byte(http.StatusText(httpResp.StatusCode))
+		}
+		return resp, errors.NewStatusCoder(httpResp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	err = UnmarshalHTTPResponse(&resp, httpResp)
+	if err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
 var LogHTTPRequests bool
 
 func HTTPRequest(ctx context.Context, method string, urlStr string, body io.Reader, headers http.Header) (*http.Response, error) {
@@ -113,7 +205,7 @@ func HTTPRequest(ctx context.Context, method string, urlStr string, body io.Read
 	c := &http.Client{}
 	resp, err := c.Do(req)
 	if err != nil {
-		if _, ok := err.(*url.Error); ok {
+		if isDNSOrConnectionError(err) {
 			clearDNSForHostname()
 		}
 		return nil, err
@@ -125,6 +217,22 @@ func HTTPRequest(ctx context.Context, method string, urlStr string, body io.Read
 	return resp, nil
 }
 
+// isDNSOrConnectionError reports whether err indicates that the cached address
+// for a host may be stale (failed lookups and failed dials), as opposed to
+// errors like TLS handshake failures or bad redirects that say nothing about
+// the address.
+func isDNSOrConnectionError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return false
+}
+
 type HTTPClient struct {
 	http.Client
 	chStop chan struct{}
@@ -172,10 +280,36 @@ func (c HTTPClient) Close() {
 	close(c.chStop)
 }
 
-var unmarshalRequestRegexp = regexp.MustCompile(`(header|query|path):"([^"]*)"`)
+var unmarshalRequestRegexp = regexp.MustCompile(`(header|query|path|body):"([^"]*)"`)
 var stringType = reflect.TypeOf("")
 
-func UnmarshalHTTPRequest(into any, r *http.Request) error {
+// DefaultMaxRequestBodySize is the maximum number of bytes UnmarshalHTTPRequest
+// will read from a request body unless overridden with WithMaxBodySize.
+var DefaultMaxRequestBodySize int64 = 10 << 20
+
+type unmarshalRequestOptions struct {
+	maxBodySize int64
+}
+
+type UnmarshalRequestOption func(*unmarshalRequestOptions)
+
+// WithMaxBodySize overrides DefaultMaxRequestBodySize for a single call to
+// UnmarshalHTTPRequest.
+func WithMaxBodySize(n int64) UnmarshalRequestOption {
+	return func(opts *unmarshalRequestOptions) { opts.maxBodySize = n }
+}
+
+// UnmarshalHTTPRequest populates the fields of the struct pointed to by `into`
+// from `r` according to their `header:"..."`, `query:"..."`, `path:""`, and
+// `body:"..."` tags.  Body fields are decoded as JSON unless tagged
+// `body:"raw"`.  Bodies larger than the maximum body size produce a 413
+// *errors.StatusCoder.
+func UnmarshalHTTPRequest(into any, r *http.Request, opts ...UnmarshalRequestOption) error {
+	options := unmarshalRequestOptions{maxBodySize: DefaultMaxRequestBodySize}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	rval := reflect.ValueOf(into).Elem()
 
 	for i := 0; i < rval.Type().NumField(); i++ {
@@ -186,7 +320,7 @@ func UnmarshalHTTPRequest(into any, r *http.Request) error {
 			source := match[1]
 			var name string
 			if len(match) > 2 {
-				name = match[2]
+				name, _, _ = strings.Cut(match[2], ",")
 			}
 
 			fieldVal := rval.Field(i)
@@ -231,14 +365,22 @@ func UnmarshalHTTPRequest(into any, r *http.Request) error {
 				// }
 				unmarshal = unmarshalURLPath
 			case "body":
-				bs, err := ioutil.ReadAll(r.Body)
+				bs, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body, options.maxBodySize))
 				if err != nil {
+					var maxBytesErr *http.MaxBytesError
+					if errors.As(err, &maxBytesErr) {
+						return errors.NewStatusCoder(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", maxBytesErr.Limit))
+					}
 					return err
 				}
 				value = string(bs)
-				unmarshal = unmarshalBody
+				if name == "raw" {
+					unmarshal = unmarshalHTTPField
+				} else {
+					unmarshal = unmarshalBody
+				}
 			default:
-				panic("invariant violation")
+				Assert(false, "unknown struct tag source", "source", source, "field", field.Name)
 			}
 			if value == "" && values == nil {
 				continue
@@ -264,14 +406,14 @@ func unmarshalBody(fieldName, value string, values []string, fieldVal reflect.Va
 	return json.Unmarshal([]byte(value), fieldVal.Interface())
 }
 
-var unmarshalResponseRegexp = regexp.MustCompile(`(header):"([^"]*)"`)
+var unmarshalResponseRegexp = regexp.MustCompile(`(header|body|status):"([^"]*)"`)
 
 func UnmarshalHTTPResponse(into any, r *http.Response) error {
 	rval := reflect.ValueOf(into).Elem()
 
 	for i := 0; i < rval.Type().NumField(); i++ {
 		field := rval.Type().Field(i)
-		matches := unmarshalRequestRegexp.FindAllStringSubmatch(string(field.Tag), -1)
+		matches := unmarshalResponseRegexp.FindAllStringSubmatch(string(field.Tag), -1)
 		var found bool
 		for _, match := range matches {
 			source := match[1]
@@ -292,8 +434,22 @@ func UnmarshalHTTPResponse(into any, r *http.Response) error {
 			case "header":
 				value = r.Header.Get(name)
 				unmarshal = unmarshalHTTPHeader
+			case "status":
+				value = strconv.Itoa(r.StatusCode)
+				unmarshal = unmarshalHTTPField
+			case "body":
+				bs, err := io.ReadAll(r.Body)
+				if err != nil {
+					return err
+				}
+				value = string(bs)
+				if name == "raw" {
+					unmarshal = unmarshalHTTPField
+				} else {
+					unmarshal = unmarshalBody
+				}
 			default:
-				panic("invariant violation")
+				Assert(false, "unknown struct tag source", "source", source, "field", field.Name)
 			}
 			if value == "" {
 				continue
@@ -0,0 +1,57 @@
+package utils_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestSignedCookie(t *testing.T) {
+	secret := []byte("s3cr3t")
+
+	setCookie := func(value string) *http.Cookie {
+		w := httptest.NewRecorder()
+		utils.SetSignedCookie(w, "session", value, secret, http.Cookie{Path: "/", HttpOnly: true})
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		return cookies[0]
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		cookie := setCookie("user=42")
+		require.Equal(t, "/", cookie.Path)
+		require.True(t, cookie.HttpOnly)
+
+		r := httptest.NewRequest("GET", "/", nil)
+		r.AddCookie(cookie)
+		value, err := utils.GetSignedCookie(r, "session", secret)
+		require.NoError(t, err)
+		require.Equal(t, "user=42", value)
+
+		_, err = utils.GetSignedCookie(r, "session", []byte("other secret"))
+		require.ErrorIs(t, err, utils.ErrInvalidCookieSignature)
+	})
+
+	t.Run("tampered value is rejected", func(t *testing.T) {
+		cookie := setCookie("user=42")
+		_, sig, _ := strings.Cut(cookie.Value, ".")
+		cookie.Value = base64.RawURLEncoding.EncodeToString([]byte("user=1")) + "." + sig
+
+		r := httptest.NewRequest("GET", "/", nil)
+		r.AddCookie(cookie)
+		_, err := utils.GetSignedCookie(r, "session", secret)
+		require.ErrorIs(t, err, utils.ErrInvalidCookieSignature)
+	})
+
+	t.Run("missing cookie", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		_, err := utils.GetSignedCookie(r, "session", secret)
+		require.ErrorIs(t, err, http.ErrNoCookie)
+	})
+}
@@ -0,0 +1,233 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+type downloadOptions struct {
+	client       HTTPDoer
+	headers      http.Header
+	onProgress   func(written, total int64)
+	expectedETag string
+}
+
+type DownloadOption func(*downloadOptions)
+
+// WithDownloadClient sets the client used to make download requests.  The
+// default is http.DefaultClient.
+func WithDownloadClient(client HTTPDoer) DownloadOption {
+	return func(opts *downloadOptions) { opts.client = client }
+}
+
+// WithDownloadHeaders sets additional headers to send with download requests.
+func WithDownloadHeaders(headers http.Header) DownloadOption {
+	return func(opts *downloadOptions) { opts.headers = headers }
+}
+
+// WithDownloadProgress sets a callback that receives the number of bytes
+// written to the destination so far (including any previously downloaded
+// portion) and the total expected size (-1 if unknown).
+func WithDownloadProgress(fn func(written, total int64)) DownloadOption {
+	return func(opts *downloadOptions) { opts.onProgress = fn }
+}
+
+// WithDownloadETag causes Download to fail if the server's ETag for the
+// resource doesn't match `etag`.
+func WithDownloadETag(etag string) DownloadOption {
+	return func(opts *downloadOptions) { opts.expectedETag = etag }
+}
+
+// Download streams the resource at `urlStr` to `destPath`.  Data is written to
+// `destPath + ".part"` and atomically renamed into place once the transfer is
+// complete and its size has been verified.
+//
+// The first response's strong ETag (or else its Last-Modified time) is saved
+// next to the partial file, in `destPath + ".part.validator"`.  If a partial
+// file is left over from an earlier attempt, Download resumes it with a `Range`
+// request carrying that validator (or the WithDownloadETag value) as
+// `If-Range`, so that a resource that has changed in the meantime is downloaded
+// again from the beginning instead of being stitched onto the stale prefix.  A
+// partial file with no validator is discarded, as are partial files on servers
+// that ignore the range.
+func Download(ctx context.Context, urlStr string, destPath string, opts ...DownloadOption) error {
+	options := downloadOptions{client: http.DefaultClient}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	partPath := destPath + ".part"
+	validatorPath := partPath + ".validator"
+
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	ifRange := options.expectedETag
+	if ifRange == "" && offset > 0 {
+		bs, err := os.ReadFile(validatorPath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		ifRange = string(bs)
+	}
+	if ifRange == "" {
+		// Without a validator we can't tell whether the partial file is stale
+		offset = 0
+	}
+
+	resp, err := downloadRequest(ctx, urlStr, offset, ifRange, options)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0 {
+		// The partial file is unusable (e.g. the resource shrank), so start over
+		resp.Body.Close()
+		offset = 0
+		resp, err = downloadRequest(ctx, urlStr, offset, ifRange, options)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+	}
+
+	var total int64 = -1
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusOK:
+		offset = 0
+		flags |= os.O_TRUNC
+		total = resp.ContentLength
+	case http.StatusPartialContent:
+		start, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return err
+		} else if start != offset {
+			return errors.Errorf("server resumed at byte %d, expected %d", start, offset)
+		}
+		if etag := resp.Header.Get("ETag"); etag != "" && isETag(ifRange) && etag != ifRange {
+			return errors.Errorf("server resumed a different version of the resource: expected etag %v, got %v", ifRange, etag)
+		}
+		flags |= os.O_APPEND
+		total = size
+	default:
+		return errors.NewStatusCoder(resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	if options.expectedETag != "" && resp.Header.Get("ETag") != options.expectedETag {
+		return errors.Errorf("etag mismatch: expected %v, got %v", options.expectedETag, resp.Header.Get("ETag"))
+	}
+
+	f, err := os.OpenFile(partPath, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		// Only record the new validator once the stale partial file is gone, so
+		// that the two always describe the same version of the resource
+		err = os.WriteFile(validatorPath, []byte(responseValidator(resp)), 0o644)
+		if err != nil {
+			return err
+		}
+	}
+
+	body := NewProgressReader(resp.Body, func(bytesRead int64) {
+		if options.onProgress != nil {
+			options.onProgress(offset+bytesRead, total)
+		}
+	})
+
+	written, err := io.Copy(f, body)
+	if err != nil {
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	if total >= 0 && offset+written != total {
+		return errors.Errorf("incomplete download: got %d of %d bytes", offset+written, total)
+	}
+	err = os.Rename(partPath, destPath)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(validatorPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func downloadRequest(ctx context.Context, urlStr string, offset int64, ifRange string, options downloadOptions) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range options.headers {
+		req.Header[k] = vs
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", ifRange)
+	}
+	return options.client.Do(req)
+}
+
+// responseValidator returns the value to send as `If-Range` when resuming
+// `resp`: its ETag if that's strong (If-Range doesn't allow weak ETags), or
+// else its Last-Modified time, or "" if it has neither.
+func responseValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+func isETag(validator string) bool {
+	return strings.HasPrefix(validator, `"`)
+}
+
+// parseContentRange parses a `Content-Range: bytes start-end/size` header.  The
+// size is -1 if the server reported it as unknown (`*`).
+func parseContentRange(header string) (start int64, size int64, err error) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, 0, errors.Errorf("invalid Content-Range: %q", header)
+	}
+	rng, sizeStr, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, errors.Errorf("invalid Content-Range: %q", header)
+	}
+	startStr, _, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, 0, errors.Errorf("invalid Content-Range: %q", header)
+	}
+	start, err = strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid Content-Range: %q", header)
+	}
+	if sizeStr == "*" {
+		return start, -1, nil
+	}
+	size, err = strconv.ParseInt(sizeStr, 10, 64)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "invalid Content-Range: %q", header)
+	}
+	return start, size, nil
+}
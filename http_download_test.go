@@ -0,0 +1,177 @@
+package utils_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	t.Run("full download", func(t *testing.T) {
+		ranges = nil
+		dest := filepath.Join(t.TempDir(), "data.bin")
+
+		var lastWritten, lastTotal int64
+		err := utils.Download(context.Background(), srv.URL, dest, utils.WithDownloadProgress(func(written, total int64) {
+			lastWritten, lastTotal = written, total
+		}))
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		require.Equal(t, content, got)
+		require.Equal(t, []string{""}, ranges)
+		require.Equal(t, int64(len(content)), lastWritten)
+		require.Equal(t, int64(len(content)), lastTotal)
+
+		_, err = os.Stat(dest + ".part")
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("resumes a partial download", func(t *testing.T) {
+		ranges = nil
+		dest := filepath.Join(t.TempDir(), "data.bin")
+		err := os.WriteFile(dest+".part", content[:4000], 0o644)
+		require.NoError(t, err)
+
+		err = utils.Download(context.Background(), srv.URL, dest, utils.WithDownloadETag(`"v1"`))
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		require.Equal(t, content, got)
+		require.Equal(t, []string{"bytes=4000-"}, ranges)
+	})
+
+	t.Run("etag mismatch", func(t *testing.T) {
+		dest := filepath.Join(t.TempDir(), "data.bin")
+		err := utils.Download(context.Background(), srv.URL, dest, utils.WithDownloadETag(`"v2"`))
+		require.ErrorContains(t, err, "etag mismatch")
+
+		_, err = os.Stat(dest)
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("a partial file without a validator is discarded", func(t *testing.T) {
+		ranges = nil
+		dest := filepath.Join(t.TempDir(), "data.bin")
+		err := os.WriteFile(dest+".part", []byte("stale"), 0o644)
+		require.NoError(t, err)
+
+		err = utils.Download(context.Background(), srv.URL, dest)
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		require.Equal(t, content, got)
+		require.Equal(t, []string{""}, ranges)
+	})
+}
+
+// versionedResource serves `content` with the given ETag.  While `interrupt`
+// is set, it aborts every response after the first 4000 bytes.
+type versionedResource struct {
+	content   []byte
+	etag      string
+	interrupt bool
+	ifRanges  []string
+}
+
+func (res *versionedResource) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	res.ifRanges = append(res.ifRanges, r.Header.Get("If-Range"))
+	w.Header().Set("ETag", res.etag)
+	if res.interrupt {
+		w.Header().Set("Content-Length", strconv.Itoa(len(res.content)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(res.content[:4000])
+		panic(http.ErrAbortHandler)
+	}
+	http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(res.content))
+}
+
+func TestDownload_ResumeUsesSavedValidator(t *testing.T) {
+	v1 := bytes.Repeat([]byte("a"), 10000)
+	v2 := bytes.Repeat([]byte("b"), 10000)
+
+	// interruptedDownload leaves a partial download of v1 in a temp dir
+	interruptedDownload := func(t *testing.T) (*versionedResource, *httptest.Server, string) {
+		res := &versionedResource{content: v1, etag: `"v1"`, interrupt: true}
+		srv := httptest.NewServer(res)
+		t.Cleanup(srv.Close)
+
+		dest := filepath.Join(t.TempDir(), "data.bin")
+		err := utils.Download(context.Background(), srv.URL, dest)
+		require.Error(t, err)
+
+		part, err := os.ReadFile(dest + ".part")
+		require.NoError(t, err)
+		require.Equal(t, v1[:4000], part)
+
+		res.interrupt = false
+		return res, srv, dest
+	}
+
+	for _, changed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("changed=%v", changed), func(t *testing.T) {
+			res, srv, dest := interruptedDownload(t)
+
+			expected := v1
+			if changed {
+				// The resource changes (keeping its length) before the download is resumed
+				res.content, res.etag, expected = v2, `"v2"`, v2
+			}
+
+			err := utils.Download(context.Background(), srv.URL, dest)
+			require.NoError(t, err)
+
+			got, err := os.ReadFile(dest)
+			require.NoError(t, err)
+			require.Equal(t, expected, got)
+			require.Equal(t, []string{"", `"v1"`}, res.ifRanges)
+
+			_, err = os.Stat(dest + ".part.validator")
+			require.True(t, os.IsNotExist(err))
+		})
+	}
+
+	t.Run("an etag mismatch leaves the saved validator alone", func(t *testing.T) {
+		res, srv, dest := interruptedDownload(t)
+		res.content, res.etag = v2, `"v2"`
+
+		err := utils.Download(context.Background(), srv.URL, dest, utils.WithDownloadETag(`"v1"`))
+		require.ErrorContains(t, err, "etag mismatch")
+
+		validator, err := os.ReadFile(dest + ".part.validator")
+		require.NoError(t, err)
+		require.Equal(t, `"v1"`, string(validator))
+
+		err = utils.Download(context.Background(), srv.URL, dest)
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		require.Equal(t, v2, got)
+		require.Equal(t, []string{"", `"v1"`, `"v1"`}, res.ifRanges)
+	})
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsDNSOrConnectionError(t *testing.T) {
+	dialTimeout := &url.Error{Op: "Get", URL: "http://example.com", Err: &net.OpError{
+		Op:  "dial",
+		Net: "tcp",
+		Err: context.DeadlineExceeded,
+	}}
+	require.True(t, isDNSOrConnectionError(dialTimeout))
+
+	dnsFailure := &url.Error{Op: "Get", URL: "http://example.com", Err: &net.OpError{
+		Op:  "dial",
+		Net: "tcp",
+		Err: &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true},
+	}}
+	require.True(t, isDNSOrConnectionError(dnsFailure))
+
+	tlsAlert := &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{
+		Op:  "remote error",
+		Err: context.Canceled,
+	}}
+	require.False(t, isDNSOrConnectionError(tlsAlert))
+}
+
+func TestHTTPRequest_DNSCacheEviction(t *testing.T) {
+	t.Run("TLS handshake error doesn't evict", func(t *testing.T) {
+		srv := httptest.NewTLSServer(nil)
+		defer srv.Close()
+		defer dnsCache.Delete("127.0.0.1")
+
+		_, err := HTTPRequest(context.Background(), "GET", srv.URL, nil, nil)
+		require.Error(t, err)
+		_, cached := dnsCache.Get("127.0.0.1")
+		require.True(t, cached)
+	})
+
+	t.Run("dial error evicts", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := ln.Addr().String()
+		ln.Close()
+		defer dnsCache.Delete("127.0.0.1")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = HTTPRequest(ctx, "GET", "http://"+addr, nil, nil)
+		require.Error(t, err)
+		_, cached := dnsCache.Get("127.0.0.1")
+		require.False(t, cached)
+	})
+}
@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+type URLQueryMarshaler interface {
+	MarshalURLQuery() ([]string, error)
+}
+
+// MarshalQuery is the inverse of the `query:"..."` handling in
+// UnmarshalHTTPRequest.  It encodes the struct fields tagged with
+// `query:"name"` into url.Values.  Nil pointers are always skipped, and zero
+// values are skipped when the tag carries the `omitempty` option (e.g.
+// `query:"name,omitempty"`).
+func MarshalQuery(v any) (url.Values, error) {
+	rval := reflect.ValueOf(v)
+	for rval.Kind() == reflect.Ptr {
+		if rval.IsNil() {
+			return url.Values{}, nil
+		}
+		rval = rval.Elem()
+	}
+	if rval.Kind() != reflect.Struct {
+		return nil, errors.Errorf("cannot marshal %v into a query string", rval.Type())
+	}
+
+	values := url.Values{}
+	for i := 0; i < rval.NumField(); i++ {
+		field := rval.Type().Field(i)
+		tag, ok := field.Tag.Lookup("query")
+		if !ok || field.PkgPath != "" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		omitEmpty := opts == "omitempty"
+
+		fieldVal := rval.Field(i)
+		if fieldVal.Kind() == reflect.Ptr && fieldVal.IsNil() {
+			continue
+		} else if omitEmpty && fieldVal.IsZero() {
+			continue
+		}
+
+		strs, err := marshalURLQuery(field.Name, fieldVal)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range strs {
+			values.Add(name, s)
+		}
+	}
+	return values, nil
+}
+
+func marshalURLQuery(fieldName string, fieldVal reflect.Value) ([]string, error) {
+	if as, is := fieldVal.Interface().(URLQueryMarshaler); is {
+		return as.MarshalURLQuery()
+	}
+
+	if fieldVal.Kind() == reflect.Slice && fieldVal.Type().Elem().Kind() != reflect.Uint8 {
+		if _, is := fieldVal.Interface().(encoding.TextMarshaler); !is {
+			strs := make([]string, 0, fieldVal.Len())
+			for i := 0; i < fieldVal.Len(); i++ {
+				s, err := marshalHTTPField(fieldName, fieldVal.Index(i))
+				if err != nil {
+					return nil, err
+				}
+				strs = append(strs, s)
+			}
+			return strs, nil
+		}
+	}
+
+	s, err := marshalHTTPField(fieldName, fieldVal)
+	if err != nil {
+		return nil, err
+	}
+	return []string{s}, nil
+}
+
+func marshalHTTPField(fieldName string, fieldVal reflect.Value) (string, error) {
+	if as, is := fieldVal.Interface().(encoding.TextMarshaler); is {
+		bs, err := as.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(bs), nil
+	}
+
+	if fieldVal.Kind() == reflect.Slice && fieldVal.Type().Elem().Kind() == reflect.Uint8 {
+		return string(fieldVal.Bytes()), nil
+	}
+
+	switch fieldVal.Kind() {
+	case reflect.Ptr:
+		if fieldVal.IsNil() {
+			return "", nil
+		}
+		return marshalHTTPField(fieldName, fieldVal.Elem())
+	case reflect.String:
+		return fieldVal.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fieldVal.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fieldVal.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(fieldVal.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(fieldVal.Float(), 'f', -1, 64), nil
+	case reflect.Bool:
+		return strconv.FormatBool(fieldVal.Bool()), nil
+	default:
+		return "", errors.Errorf(`cannot marshal field "%v" of type %v`, fieldName, fieldVal.Type())
+	}
+}
+
+// marshalHTTPRequest is the inverse of UnmarshalHTTPRequest.  It collects the
+// struct fields tagged with `query:"..."`, `header:"..."`, and `body:"..."`
+// into their respective parts of an outbound request.  Body fields are encoded
+// as JSON unless tagged `body:"raw"`, in which case a string or []byte field is
+// sent as-is.
+func marshalHTTPRequest(v any) (query url.Values, header http.Header, body []byte, err error) {
+	query, err = MarshalQuery(v)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	header = http.Header{}
+	rval := reflect.ValueOf(v)
+	for rval.Kind() == reflect.Ptr {
+		if rval.IsNil() {
+			return query, header, nil, nil
+		}
+		rval = rval.Elem()
+	}
+
+	for i := 0; i < rval.NumField(); i++ {
+		field := rval.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		fieldVal := rval.Field(i)
+
+		if name, ok := field.Tag.Lookup("header"); ok {
+			if fieldVal.Kind() == reflect.Ptr && fieldVal.IsNil() {
+				continue
+			}
+			s, err := marshalHTTPField(field.Name, fieldVal)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if s != "" {
+				header.Set(name, s)
+			}
+		}
+
+		if encoding, ok := field.Tag.Lookup("body"); ok {
+			if body != nil {
+				return nil, nil, nil, errors.Errorf("multiple body fields ('%v')", field.Name)
+			}
+			if encoding == "raw" {
+				s, err := marshalHTTPField(field.Name, fieldVal)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+				body = []byte(s)
+			} else {
+				body, err = json.Marshal(fieldVal.Interface())
+				if err != nil {
+					return nil, nil, nil, err
+				}
+				if header.Get("Content-Type") == "" {
+					header.Set("Content-Type", "application/json")
+				}
+			}
+		}
+	}
+	return query, header, body, nil
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ServeContentRange responds to `r` with the `size` bytes available from
+// `readerAt`, honoring a single-range `Range` header: valid ranges get a 206
+// with the matching `Content-Range`, unsatisfiable ranges get a 416, and
+// requests without a (usable) range get a 200 with the full content.  To serve
+// a non-seekable stream, wrap it in a BufferedReadSeeker, which implements
+// io.ReaderAt.
+func ServeContentRange(w http.ResponseWriter, r *http.Request, size int64, readerAt io.ReaderAt) {
+	w.Header().Set("Accept-Ranges", "bytes")
+
+	start, length, ok, satisfiable := parseRangeHeader(r.Header.Get("Range"), size)
+	if !ok {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		w.WriteHeader(http.StatusOK)
+		if r.Method != http.MethodHead {
+			io.Copy(w, io.NewSectionReader(readerAt, 0, size))
+		}
+		return
+	} else if !satisfiable {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+		http.Error(w, http.StatusText(http.StatusRequestedRangeNotSatisfiable), http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+
+	w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, size))
+	w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+	w.WriteHeader(http.StatusPartialContent)
+	if r.Method != http.MethodHead {
+		io.Copy(w, io.NewSectionReader(readerAt, start, length))
+	}
+}
+
+// parseRangeHeader parses a single-range `Range: bytes=...` header against a
+// resource of the given size.  `ok` is false if there's no range to honor
+// (missing, malformed, or multi-range headers), in which case the full content
+// should be served.
+func parseRangeHeader(header string, size int64) (start, length int64, ok bool, satisfiable bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, false
+	}
+	startStr, endStr, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, false
+	}
+
+	if startStr == "" {
+		// Suffix range: the last N bytes
+		n, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, false, false
+		} else if n == 0 || size == 0 {
+			return 0, 0, true, false
+		} else if n > size {
+			n = size
+		}
+		return size - n, n, true, true
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false, false
+	} else if start >= size {
+		return 0, 0, true, false
+	}
+
+	end := size - 1
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, false
+		} else if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end - start + 1, true, true
+}
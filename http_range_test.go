@@ -0,0 +1,62 @@
+package utils_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestServeContentRange(t *testing.T) {
+	const data = "abcdefghijklmnopqrstuvwxyz"
+
+	serve := func(rangeHeader string) *http.Response {
+		r := httptest.NewRequest("GET", "/", nil)
+		if rangeHeader != "" {
+			r.Header.Set("Range", rangeHeader)
+		}
+		w := httptest.NewRecorder()
+		brs := utils.NewBufferedReadSeeker(strings.NewReader(data))
+		utils.ServeContentRange(w, r, int64(len(data)), brs)
+		return w.Result()
+	}
+
+	t.Run("single range", func(t *testing.T) {
+		resp := serve("bytes=2-5")
+		require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		require.Equal(t, "bytes 2-5/26", resp.Header.Get("Content-Range"))
+		body, _ := io.ReadAll(resp.Body)
+		require.Equal(t, "cdef", string(body))
+	})
+
+	t.Run("open-ended and suffix ranges", func(t *testing.T) {
+		resp := serve("bytes=20-")
+		require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		require.Equal(t, "uvwxyz", string(body))
+
+		resp = serve("bytes=-3")
+		require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		require.Equal(t, "bytes 23-25/26", resp.Header.Get("Content-Range"))
+		body, _ = io.ReadAll(resp.Body)
+		require.Equal(t, "xyz", string(body))
+	})
+
+	t.Run("unsatisfiable range", func(t *testing.T) {
+		resp := serve("bytes=100-200")
+		require.Equal(t, http.StatusRequestedRangeNotSatisfiable, resp.StatusCode)
+		require.Equal(t, "bytes */26", resp.Header.Get("Content-Range"))
+	})
+
+	t.Run("no range header", func(t *testing.T) {
+		resp := serve("")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		require.Equal(t, data, string(body))
+	})
+}
@@ -1,13 +1,18 @@
 package utils_test
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/brynbellomy/go-utils"
+	"github.com/brynbellomy/go-utils/errors"
 	"github.com/brynbellomy/go-utils/fn"
 )
 
@@ -82,3 +87,174 @@ func TestUnmarshalHTTPField(t *testing.T) {
 	require.Equal(t, Alias(999), *req.QueryPtrAlias)
 	require.Equal(t, []Alias{111, 222, 333}, req.QueryAliasArray)
 }
+
+func TestFormRequest(t *testing.T) {
+	var received url.Values
+	var contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		err := r.ParseForm()
+		require.NoError(t, err)
+		received = r.PostForm
+		utils.RespondJSON(w, map[string]string{"status": "ok"})
+	}))
+	defer srv.Close()
+
+	values := url.Values{
+		"name":  {"Jane Doe"},
+		"tags":  {"a", "b&c"},
+		"empty": {""},
+	}
+
+	var response struct {
+		Status string `json:"status"`
+	}
+	_, code, err := utils.FormRequest(context.Background(), "POST", srv.URL, values, nil, &response)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "application/x-www-form-urlencoded", contentType)
+	require.Equal(t, values, received)
+	require.Equal(t, "ok", response.Status)
+
+	t.Run("raw response", func(t *testing.T) {
+		var raw []byte
+		_, _, err := utils.FormRequest(context.Background(), "POST", srv.URL, values, nil, &raw)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"status":"ok"}`, string(raw))
+	})
+}
+
+func TestMarshalQuery(t *testing.T) {
+	type request struct {
+		Name      string   `query:"name"`
+		Count     int      `query:"count"`
+		Enabled   *bool    `query:"enabled"`
+		Missing   *bool    `query:"missing"`
+		Tags      []string `query:"tags"`
+		IDs       []Alias  `query:"ids"`
+		Skipped   string   `query:"skipped,omitempty"`
+		Zero      int      `query:"zero"`
+		Untouched string
+	}
+
+	in := request{
+		Name:    "jane",
+		Count:   3,
+		Enabled: utils.PtrTo(false),
+		Tags:    []string{"a", "b"},
+		IDs:     []Alias{7, 8},
+	}
+
+	values, err := utils.MarshalQuery(in)
+	require.NoError(t, err)
+	require.Equal(t, url.Values{
+		"name":    {"jane"},
+		"count":   {"3"},
+		"enabled": {"false"},
+		"tags":    {"a", "b"},
+		"ids":     {"7", "8"},
+		"zero":    {"0"},
+	}, values)
+
+	r, err := http.NewRequest("GET", "http://localhost?"+values.Encode(), nil)
+	require.NoError(t, err)
+
+	var out request
+	err = utils.UnmarshalHTTPRequest(&out, r)
+	require.NoError(t, err)
+	require.Equal(t, in, out)
+}
+
+func TestCall(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	type request struct {
+		Limit  int    `query:"limit"`
+		Cursor string `query:"cursor,omitempty"`
+		Token  string `header:"X-Token"`
+		Item   item   `body:"json"`
+	}
+	type response struct {
+		Status    int    `status:""`
+		RequestID string `header:"X-Request-Id"`
+		Item      item   `body:"json"`
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			http.Error(w, "bad token", http.StatusUnauthorized)
+			return
+		}
+		require.Equal(t, "5", r.URL.Query().Get("limit"))
+		require.False(t, r.URL.Query().Has("cursor"))
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var body item
+		err := json.NewDecoder(r.Body).Decode(&body)
+		require.NoError(t, err)
+
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(item{Name: body.Name + "!"})
+	}))
+	defer srv.Close()
+
+	resp, err := utils.Call[request, response](context.Background(), nil, "POST", srv.URL, request{
+		Limit: 5,
+		Token: "secret",
+		Item:  item{Name: "widget"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.Status)
+	require.Equal(t, "req-1", resp.RequestID)
+	require.Equal(t, "widget!", resp.Item.Name)
+
+	t.Run("error status returns a StatusCoder", func(t *testing.T) {
+		_, err := utils.Call[request, response](context.Background(), srv.Client(), "POST", srv.URL, request{Limit: 5})
+		require.True(t, errors.IsStatusCoder(err, http.StatusUnauthorized))
+		sc, _ := errors.AsStatusCoder(err)
+		require.Equal(t, "bad token", sc.Message)
+	})
+}
+
+func TestUnmarshalHTTPRequest_Body(t *testing.T) {
+	type request struct {
+		Payload struct {
+			Name string `json:"name"`
+		} `body:"json"`
+	}
+
+	t.Run("under the limit", func(t *testing.T) {
+		r, err := http.NewRequest("POST", "http://localhost", strings.NewReader(`{"name":"widget"}`))
+		require.NoError(t, err)
+
+		var req request
+		err = utils.UnmarshalHTTPRequest(&req, r, utils.WithMaxBodySize(1024))
+		require.NoError(t, err)
+		require.Equal(t, "widget", req.Payload.Name)
+	})
+
+	t.Run("over the limit", func(t *testing.T) {
+		body := `{"name":"` + strings.Repeat("x", 100) + `"}`
+		r, err := http.NewRequest("POST", "http://localhost", strings.NewReader(body))
+		require.NoError(t, err)
+
+		var req request
+		err = utils.UnmarshalHTTPRequest(&req, r, utils.WithMaxBodySize(16))
+		require.True(t, errors.IsStatusCoder(err, http.StatusRequestEntityTooLarge))
+	})
+
+	t.Run("raw body", func(t *testing.T) {
+		type rawRequest struct {
+			Body []byte `body:"raw"`
+		}
+		r, err := http.NewRequest("POST", "http://localhost", strings.NewReader("hello"))
+		require.NoError(t, err)
+
+		var req rawRequest
+		err = utils.UnmarshalHTTPRequest(&req, r)
+		require.NoError(t, err)
+		require.Equal(t, []byte("hello"), req.Body)
+	})
+}
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"bytes"
+	stderrors "errors"
 	"io"
+	"time"
 
 	"github.com/brynbellomy/go-utils/errors"
 )
@@ -102,3 +104,109 @@ func (brs *BufferedReadSeeker) ReadAt(p []byte, off int64) (n int, err error) {
 	}
 	return brs.Read(p)
 }
+
+// ProgressReader wraps an io.Reader and invokes `OnProgress` with the running
+// total of bytes read after every read.
+type ProgressReader struct {
+	Reader     io.Reader
+	OnProgress func(bytesRead int64)
+	bytesRead  int64
+}
+
+func NewProgressReader(r io.Reader, onProgress func(bytesRead int64)) *ProgressReader {
+	return &ProgressReader{Reader: r, OnProgress: onProgress}
+}
+
+func (pr *ProgressReader) Read(p []byte) (int, error) {
+	n, err := pr.Reader.Read(p)
+	if n > 0 {
+		pr.bytesRead += int64(n)
+		if pr.OnProgress != nil {
+			pr.OnProgress(pr.bytesRead)
+		}
+	}
+	return n, err
+}
+
+func (pr *ProgressReader) BytesRead() int64 {
+	return pr.bytesRead
+}
+
+var ErrThroughputTooLow = errors.New("read throughput below minimum")
+
+// MinThroughputReader wraps `r` and fails with ErrThroughputTooLow once the
+// read rate drops below `minBytesPerSec`, to abort stalled (e.g. slow-loris)
+// streams.
+//
+// The rate is evaluated over consecutive windows of `window` duration, and only
+// time spent blocked inside Read counts toward a window -- time the caller
+// spends between reads doesn't, so a slow consumer isn't mistaken for a slow
+// producer.  The rate is only checked once a full window has elapsed, after
+// which the window starts over, so a brief pause is averaged against the rest
+// of its window and never carries over into the next one.
+//
+// Note that the check happens when Read returns; a Read that blocks forever
+// without returning any data must be interrupted some other way (e.g. a
+// connection deadline).
+func MinThroughputReader(r io.Reader, minBytesPerSec int64, window time.Duration) io.Reader {
+	return &minThroughputReader{r: r, minBytesPerSec: minBytesPerSec, window: window}
+}
+
+type minThroughputReader struct {
+	r              io.Reader
+	minBytesPerSec int64
+	window         time.Duration
+
+	elapsed time.Duration
+	n       int64
+}
+
+func (mr *minThroughputReader) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := mr.r.Read(p)
+	mr.elapsed += time.Since(start)
+	mr.n += int64(n)
+
+	if mr.elapsed >= mr.window {
+		rate := float64(mr.n) / mr.elapsed.Seconds()
+		if rate < float64(mr.minBytesPerSec) && err == nil {
+			return n, errors.Wrapf(ErrThroughputTooLow, "%.0f bytes/sec over %v", rate, mr.elapsed)
+		}
+		mr.elapsed = 0
+		mr.n = 0
+	}
+	return n, err
+}
+
+// TolerantMultiWriter is like io.MultiWriter, except that an error from one
+// writer doesn't stop the write from reaching the rest.  Every write goes to
+// every writer; the errors from any that fail are joined (errors.Join), each
+// naming the writer's position and type.  The returned count is len(p) unless
+// every writer failed, in which case it's 0.
+func TolerantMultiWriter(writers ...io.Writer) io.Writer {
+	return &tolerantMultiWriter{writers: writers}
+}
+
+type tolerantMultiWriter struct {
+	writers []io.Writer
+}
+
+func (mw *tolerantMultiWriter) Write(p []byte) (int, error) {
+	var errs []error
+	healthy := 0
+	for i, w := range mw.writers {
+		n, err := w.Write(p)
+		if err == nil && n < len(p) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "writer %d (%T)", i, w))
+			continue
+		}
+		healthy++
+	}
+	if healthy == 0 && len(mw.writers) > 0 {
+		return 0, stderrors.Join(errs...)
+	}
+	return len(p), stderrors.Join(errs...)
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+// RotatingWriter is an io.WriteCloser that appends to a file, rotating it to
+// `path.1` (shifting existing backups to `path.2`, `path.3`, ...) whenever a
+// write would push it past `maxSize` bytes.  At most `maxBackups` backups are
+// kept; older ones are deleted.  A single write larger than `maxSize` is never
+// split, so it may produce a file over the limit.  It's safe for concurrent
+// use.
+type RotatingWriter struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+var _ io.WriteCloser = (*RotatingWriter)(nil)
+
+func NewRotatingWriter(path string, maxSize int64, maxBackups int) (*RotatingWriter, error) {
+	w := &RotatingWriter{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	err := w.open()
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, errors.ErrClosed
+	}
+
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		err := w.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *RotatingWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+func (w *RotatingWriter) rotate() error {
+	err := w.file.Close()
+	w.file = nil
+	if err != nil {
+		return err
+	}
+
+	if w.maxBackups <= 0 {
+		err = os.Remove(w.path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return w.open()
+	}
+
+	err = os.Remove(w.backupPath(w.maxBackups))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := w.maxBackups - 1; i >= 1; i-- {
+		err = os.Rename(w.backupPath(i), w.backupPath(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	err = os.Rename(w.path, w.backupPath(1))
+	if err != nil {
+		return err
+	}
+	return w.open()
+}
+
+func (w *RotatingWriter) backupPath(i int) string {
+	return fmt.Sprintf("%v.%d", w.path, i)
+}
@@ -0,0 +1,93 @@
+package utils_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestRotatingWriter(t *testing.T) {
+	t.Run("rotates and caps backups", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		w, err := utils.NewRotatingWriter(path, 10, 2)
+		require.NoError(t, err)
+		defer w.Close()
+
+		for i := 0; i < 5; i++ {
+			_, err := fmt.Fprintf(w, "line %d\n", i) // 7 bytes each
+			require.NoError(t, err)
+		}
+
+		requireFile(t, path, "line 4\n")
+		requireFile(t, path+".1", "line 3\n")
+		requireFile(t, path+".2", "line 2\n")
+		require.NoFileExists(t, path+".3")
+	})
+
+	t.Run("appends to an existing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		require.NoError(t, os.WriteFile(path, []byte("12345"), 0644))
+
+		w, err := utils.NewRotatingWriter(path, 10, 1)
+		require.NoError(t, err)
+		_, err = w.Write([]byte("678"))
+		require.NoError(t, err)
+		_, err = w.Write([]byte("90ab"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		requireFile(t, path, "90ab")
+		requireFile(t, path+".1", "12345678")
+
+		_, err = w.Write([]byte("x"))
+		require.Error(t, err)
+	})
+
+	t.Run("concurrent writes preserve data", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		w, err := utils.NewRotatingWriter(path, 64, 100)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 25; i++ {
+					_, err := fmt.Fprintf(w, "g%d-%02d\n", g, i)
+					require.NoError(t, err)
+				}
+			}()
+		}
+		wg.Wait()
+		require.NoError(t, w.Close())
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Greater(t, len(entries), 1)
+
+		var lines []string
+		for _, e := range entries {
+			bs, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(bs), 64)
+			lines = append(lines, strings.Fields(string(bs))...)
+		}
+		require.Len(t, lines, 100)
+	})
+}
+
+func requireFile(t *testing.T, path, expected string) {
+	t.Helper()
+	bs, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(bs))
+}
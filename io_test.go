@@ -1,6 +1,7 @@
 package utils_test
 
 import (
+	"errors"
 	"io"
 	"strings"
 	"sync"
@@ -114,3 +115,93 @@ func TestBufferedReadSeeker(t *testing.T) {
 		wg.Wait()
 	})
 }
+
+type trickleReader struct {
+	remaining int
+	delay     time.Duration
+}
+
+func (r *trickleReader) Read(p []byte) (int, error) {
+	if r.remaining == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	r.remaining--
+	p[0] = 'x'
+	return 1, nil
+}
+
+func TestMinThroughputReader(t *testing.T) {
+	t.Run("fast stream", func(t *testing.T) {
+		data := strings.Repeat("x", 1<<20)
+		r := utils.MinThroughputReader(strings.NewReader(data), 1024, 10*time.Millisecond)
+		got, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, data, string(got))
+	})
+
+	t.Run("slow trickle errors after the window", func(t *testing.T) {
+		// ~200 bytes/sec against a 10 KB/sec minimum
+		r := utils.MinThroughputReader(&trickleReader{remaining: 1000, delay: 5 * time.Millisecond}, 10*1024, 50*time.Millisecond)
+
+		start := time.Now()
+		got, err := io.ReadAll(r)
+		require.ErrorIs(t, err, utils.ErrThroughputTooLow)
+		require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+		require.Less(t, len(got), 1000)
+	})
+
+	t.Run("pauses between reads don't count", func(t *testing.T) {
+		data := strings.Repeat("x", 64)
+		r := utils.MinThroughputReader(strings.NewReader(data), 1024, time.Millisecond)
+
+		buf := make([]byte, 8)
+		var total int
+		for {
+			n, err := r.Read(buf)
+			total += n
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			time.Sleep(2 * time.Millisecond)
+		}
+		require.Equal(t, 64, total)
+	})
+}
+
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write(p []byte) (int, error) { return 0, w.err }
+
+func TestTolerantMultiWriter(t *testing.T) {
+	errBroken := errors.New("broken sink")
+	var a, b strings.Builder
+	w := utils.TolerantMultiWriter(&a, failingWriter{errBroken}, &b)
+
+	n, err := w.Write([]byte("hello "))
+	require.Equal(t, 6, n)
+	require.ErrorIs(t, err, errBroken)
+	require.Contains(t, err.Error(), "writer 1 (utils_test.failingWriter)")
+
+	_, err = w.Write([]byte("world"))
+	require.ErrorIs(t, err, errBroken)
+	require.Equal(t, "hello world", a.String())
+	require.Equal(t, "hello world", b.String())
+
+	t.Run("no errors", func(t *testing.T) {
+		var a, b strings.Builder
+		n, err := utils.TolerantMultiWriter(&a, &b).Write([]byte("hi"))
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.Equal(t, "hi", a.String())
+		require.Equal(t, "hi", b.String())
+	})
+
+	t.Run("every writer fails", func(t *testing.T) {
+		n, err := utils.TolerantMultiWriter(failingWriter{errBroken}, failingWriter{io.ErrClosedPipe}).Write([]byte("hi"))
+		require.Equal(t, 0, n)
+		require.ErrorIs(t, err, errBroken)
+		require.ErrorIs(t, err, io.ErrClosedPipe)
+	})
+}
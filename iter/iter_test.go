@@ -0,0 +1,363 @@
+package iter_test
+
+import (
+	stditer "iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils/fn"
+	"github.com/brynbellomy/go-utils/iter"
+)
+
+func isEven(x int) bool { return x%2 == 0 }
+
+func TestFilter(t *testing.T) {
+	require.Equal(t, []int{0, 2, 4}, iter.Collect(iter.Filter(slices.Values(fn.Range(0, 6)), isEven)))
+	require.Equal(t, []int{1, 3, 5}, iter.Collect(iter.Reject(slices.Values(fn.Range(0, 6)), isEven)))
+	require.Empty(t, iter.Collect(iter.Filter(slices.Values([]int(nil)), isEven)))
+
+	t.Run("stops pulling upstream on early termination", func(t *testing.T) {
+		var pulled []int
+		upstream := func(yield func(int) bool) {
+			for i := 0; ; i++ {
+				pulled = append(pulled, i)
+				if !yield(i) {
+					return
+				}
+			}
+		}
+
+		var got []int
+		for x := range iter.Filter(upstream, isEven) {
+			got = append(got, x)
+			if len(got) == 2 {
+				break
+			}
+		}
+		require.Equal(t, []int{0, 2}, got)
+		require.Equal(t, []int{0, 1, 2}, pulled)
+	})
+}
+
+func TestFilter2(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}
+	even := func(k string, v int) bool { return isEven(v) }
+
+	require.Equal(t, map[string]int{"b": 2, "d": 4}, iter.Collect2(iter.Filter2(maps.All(m), even)))
+	require.Equal(t, map[string]int{"a": 1, "c": 3}, iter.Collect2(iter.Reject2(maps.All(m), even)))
+}
+
+func BenchmarkFilter(b *testing.B) {
+	xs := fn.Range(0, 1000)
+	for i := 0; i < b.N; i++ {
+		for x := range iter.Filter(slices.Values(xs), isEven) {
+			_ = x
+		}
+	}
+}
+
+func BenchmarkFilterChained(b *testing.B) {
+	xs := fn.Range(0, 1000)
+	notDivisibleBy3 := func(x int) bool { return x%3 != 0 }
+	under900 := func(x int) bool { return x < 900 }
+	for i := 0; i < b.N; i++ {
+		for x := range iter.Filter(iter.Filter(iter.Filter(slices.Values(xs), isEven), notDivisibleBy3), under900) {
+			_ = x
+		}
+	}
+}
+
+func BenchmarkFilterHandWritten(b *testing.B) {
+	xs := fn.Range(0, 1000)
+	for i := 0; i < b.N; i++ {
+		for _, x := range xs {
+			if isEven(x) && x%3 != 0 && x < 900 {
+				_ = x
+			}
+		}
+	}
+}
+
+func TestReduce(t *testing.T) {
+	concat := func(acc string, x int) string { return acc + strconv.Itoa(x) }
+
+	require.Equal(t, "init", iter.Reduce(slices.Values([]int(nil)), "init", concat))
+	require.Equal(t, "init7", iter.Reduce(slices.Values([]int{7}), "init", concat))
+	require.Equal(t, "0123", iter.Reduce(slices.Values(fn.Range(0, 4)), "", concat))
+}
+
+func TestSum(t *testing.T) {
+	require.Equal(t, 0, iter.Sum(slices.Values([]int(nil))))
+	require.Equal(t, 5, iter.Sum(slices.Values([]int{5})))
+	require.Equal(t, 4950, iter.Sum(slices.Values(fn.Range(0, 100))))
+	require.InDelta(t, 3.75, iter.Sum(slices.Values([]float64{1.25, 2.5})), 0.0001)
+}
+
+func naturals(pulled *int) func(yield func(int) bool) {
+	return func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			*pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func TestTake(t *testing.T) {
+	xs := slices.Values(fn.Range(0, 5))
+	require.Equal(t, []int{0, 1, 2}, iter.Collect(iter.Take(xs, 3)))
+	require.Empty(t, iter.Collect(iter.Take(xs, 0)))
+	require.Empty(t, iter.Collect(iter.Take(xs, -1)))
+	require.Equal(t, []int{0, 1, 2, 3, 4}, iter.Collect(iter.Take(xs, 10)))
+
+	t.Run("stops pulling from an infinite sequence", func(t *testing.T) {
+		var pulled int
+		require.Equal(t, []int{0, 1, 2}, iter.Collect(iter.Take(naturals(&pulled), 3)))
+		require.Equal(t, 3, pulled)
+
+		pulled = 0
+		require.Empty(t, iter.Collect(iter.Take(naturals(&pulled), 0)))
+		require.Equal(t, 0, pulled)
+	})
+}
+
+func TestDrop(t *testing.T) {
+	xs := slices.Values(fn.Range(0, 5))
+	require.Equal(t, []int{3, 4}, iter.Collect(iter.Drop(xs, 3)))
+	require.Equal(t, []int{0, 1, 2, 3, 4}, iter.Collect(iter.Drop(xs, 0)))
+	require.Equal(t, []int{0, 1, 2, 3, 4}, iter.Collect(iter.Drop(xs, -1)))
+	require.Empty(t, iter.Collect(iter.Drop(xs, 10)))
+
+	var pulled int
+	require.Equal(t, []int{2, 3}, iter.Collect(iter.Take(iter.Drop(naturals(&pulled), 2), 2)))
+}
+
+func TestTakeWhile(t *testing.T) {
+	less := func(n int) func(int) bool { return func(x int) bool { return x < n } }
+	xs := slices.Values([]int{1, 2, 5, 1, 2})
+
+	require.Equal(t, []int{1, 2}, iter.Collect(iter.TakeWhile(xs, less(3))))
+	require.Equal(t, []int{5, 1, 2}, iter.Collect(iter.DropWhile(xs, less(3))))
+	require.Empty(t, iter.Collect(iter.TakeWhile(xs, less(0))))
+	require.Equal(t, []int{1, 2, 5, 1, 2}, iter.Collect(iter.DropWhile(xs, less(0))))
+
+	var pulled int
+	require.Equal(t, []int{0, 1, 2}, iter.Collect(iter.TakeWhile(naturals(&pulled), less(3))))
+	require.Equal(t, 4, pulled)
+}
+
+func TestZip(t *testing.T) {
+	keys := slices.Values([]string{"a", "b", "c"})
+	vals := slices.Values([]int{1, 2, 3, 4, 5})
+
+	require.Equal(t, map[string]int{"a": 1, "b": 2, "c": 3}, iter.Collect2(iter.Zip(keys, vals)))
+	require.Equal(t, map[int]string{1: "a", 2: "b", 3: "c"}, iter.Collect2(iter.Zip(vals, keys)))
+	require.Empty(t, iter.Collect2(iter.Zip(keys, slices.Values([]int(nil)))))
+
+	t.Run("stops both sequences on early termination", func(t *testing.T) {
+		var pulledA, pulledB int
+		stoppedB := false
+		b := func(yield func(int) bool) {
+			defer func() { stoppedB = true }()
+			for i := 0; ; i++ {
+				pulledB++
+				if !yield(i) {
+					return
+				}
+			}
+		}
+
+		var got []int
+		for x, y := range iter.Zip(naturals(&pulledA), b) {
+			got = append(got, x+y)
+			if len(got) == 3 {
+				break
+			}
+		}
+		require.Equal(t, []int{0, 2, 4}, got)
+		require.Equal(t, 3, pulledA)
+		require.Equal(t, 3, pulledB)
+		require.True(t, stoppedB)
+	})
+}
+
+func TestEnumerate(t *testing.T) {
+	require.Equal(t,
+		map[int]string{0: "a", 1: "b", 2: "c"},
+		iter.Collect2(iter.Enumerate(slices.Values([]string{"a", "b", "c"}))),
+	)
+	require.Empty(t, iter.Collect2(iter.Enumerate(slices.Values([]string(nil)))))
+
+	t.Run("counts what it sees after a Filter", func(t *testing.T) {
+		require.Equal(t,
+			map[int]int{0: 0, 1: 2, 2: 4},
+			iter.Collect2(iter.Enumerate(iter.Filter(slices.Values(fn.Range(0, 6)), isEven))),
+		)
+	})
+
+	t.Run("early termination", func(t *testing.T) {
+		var pulled int
+		var got []int
+		for i, x := range iter.Enumerate(naturals(&pulled)) {
+			require.Equal(t, i, x)
+			got = append(got, i)
+			if i == 2 {
+				break
+			}
+		}
+		require.Equal(t, []int{0, 1, 2}, got)
+		require.Equal(t, 3, pulled)
+	})
+}
+
+func TestChunk(t *testing.T) {
+	require.Equal(t,
+		[][]int{{0, 1, 2}, {3, 4, 5}},
+		iter.Collect(iter.Chunk(slices.Values(fn.Range(0, 6)), 3)),
+	)
+	require.Equal(t,
+		[][]int{{0, 1, 2, 3}, {4, 5, 6, 7}, {8}},
+		iter.Collect(iter.Chunk(slices.Values(fn.Range(0, 9)), 4)),
+	)
+	require.Equal(t, [][]int{{0, 1}}, iter.Collect(iter.Chunk(slices.Values(fn.Range(0, 2)), 5)))
+	require.Empty(t, iter.Collect(iter.Chunk(slices.Values([]int(nil)), 3)))
+	require.Panics(t, func() { iter.Chunk(slices.Values(fn.Range(0, 2)), 0) })
+
+	t.Run("chunks aren't reused", func(t *testing.T) {
+		var chunks [][]int
+		for chunk := range iter.Chunk(slices.Values(fn.Range(0, 4)), 2) {
+			chunks = append(chunks, chunk)
+		}
+		chunks[0][0] = 100
+		require.Equal(t, [][]int{{100, 1}, {2, 3}}, chunks)
+	})
+}
+
+func TestCollect(t *testing.T) {
+	require.Equal(t, []int{0, 1, 2}, iter.Collect(slices.Values(fn.Range(0, 3))))
+	require.Nil(t, iter.Collect(slices.Values([]int(nil))))
+
+	require.Equal(t, map[string]int{"a": 1, "b": 2}, iter.Collect2(maps.All(map[string]int{"a": 1, "b": 2})))
+	require.Equal(t, map[string]int{}, iter.Collect2(maps.All(map[string]int(nil))))
+
+	t.Run("last write wins", func(t *testing.T) {
+		pairs := func(yield func(string, int) bool) {
+			_ = yield("a", 1) && yield("b", 2) && yield("a", 3)
+		}
+		require.Equal(t, map[string]int{"a": 3, "b": 2}, iter.Collect2(pairs))
+	})
+}
+
+func words(s string) stditer.Seq[string] {
+	return slices.Values(strings.Fields(s))
+}
+
+func TestFlatMap(t *testing.T) {
+	lines := slices.Values([]string{"a b", "", "c d e"})
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, iter.Collect(iter.FlatMap(lines, words)))
+	require.Empty(t, iter.Collect(iter.FlatMap(slices.Values([]string(nil)), words)))
+
+	t.Run("stops mid-sub-sequence", func(t *testing.T) {
+		var pulled int
+		var got []string
+		for w := range iter.FlatMap(lines, func(line string) stditer.Seq[string] {
+			return func(yield func(string) bool) {
+				for w := range words(line) {
+					pulled++
+					if !yield(w) {
+						return
+					}
+				}
+			}
+		}) {
+			got = append(got, w)
+			if len(got) == 3 {
+				break
+			}
+		}
+		require.Equal(t, []string{"a", "b", "c"}, got)
+		require.Equal(t, 3, pulled)
+	})
+
+	t.Run("Flatten", func(t *testing.T) {
+		seqs := slices.Values([]stditer.Seq[int]{
+			slices.Values([]int{1, 2}),
+			slices.Values([]int(nil)),
+			slices.Values([]int{3}),
+		})
+		require.Equal(t, []int{1, 2, 3}, iter.Collect(iter.Flatten(seqs)))
+	})
+}
+
+func BenchmarkFlatMap(b *testing.B) {
+	xss := make([][]int, 100)
+	for i := range xss {
+		xss[i] = fn.Range(0, 10)
+	}
+	for i := 0; i < b.N; i++ {
+		for x := range iter.FlatMap(slices.Values(xss), slices.Values) {
+			_ = x
+		}
+	}
+}
+
+func BenchmarkFlatMapHandWritten(b *testing.B) {
+	xss := make([][]int, 100)
+	for i := range xss {
+		xss[i] = fn.Range(0, 10)
+	}
+	for i := 0; i < b.N; i++ {
+		for _, xs := range xss {
+			for _, x := range xs {
+				_ = x
+			}
+		}
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	require.Equal(t, []int{3, 1, 2}, iter.Collect(iter.Distinct(slices.Values([]int{3, 1, 3, 2, 1, 2}))))
+	require.Empty(t, iter.Collect(iter.Distinct(slices.Values([]int(nil)))))
+
+	t.Run("streams", func(t *testing.T) {
+		var pulled int
+		mod3 := func(x int) int { return x % 3 }
+		require.Equal(t, []int{0, 1}, iter.Collect(iter.Take(iter.DistinctBy(naturals(&pulled), mod3), 2)))
+		require.Equal(t, 2, pulled)
+	})
+
+	t.Run("DistinctBy keeps the first element for each key", func(t *testing.T) {
+		type user struct {
+			ID   int
+			Tags []string
+		}
+		users := []user{{1, []string{"a"}}, {2, nil}, {1, []string{"b"}}}
+		require.Equal(t,
+			[]user{{1, []string{"a"}}, {2, nil}},
+			iter.Collect(iter.DistinctBy(slices.Values(users), func(u user) int { return u.ID })),
+		)
+	})
+}
+
+func TestGroupBy(t *testing.T) {
+	xs := slices.Values(fn.Range(0, 7))
+
+	require.Equal(t,
+		map[bool][]int{true: {0, 2, 4, 6}, false: {1, 3, 5}},
+		iter.GroupBy(xs, isEven),
+	)
+	require.Equal(t,
+		map[int][]int{0: {0}, 1: {1}, 2: {2}, 3: {3}, 4: {4}, 5: {5}, 6: {6}},
+		iter.GroupBy(xs, func(x int) int { return x }),
+	)
+
+	empty := iter.GroupBy(slices.Values([]int(nil)), isEven)
+	require.NotNil(t, empty)
+	require.Empty(t, empty)
+}
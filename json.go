@@ -1,10 +1,183 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
+	"reflect"
 )
 
 func PrettyJSON(x any) string {
 	j, _ := json.MarshalIndent(x, "", "    ")
 	return string(j)
 }
+
+// CanonicalJSON marshals `x` to JSON with object keys sorted at every level of
+// nesting, so that equivalent values produce identical bytes.
+func CanonicalJSON(x any) ([]byte, error) {
+	generic, err := toGenericJSON(x)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+// JSONEqual reports whether `a` and `b` have the same JSON representation,
+// ignoring object key order.
+func JSONEqual(a, b any) (bool, error) {
+	aJSON, err := CanonicalJSON(a)
+	if err != nil {
+		return false, err
+	}
+	bJSON, err := CanonicalJSON(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(aJSON, bJSON), nil
+}
+
+// JSONContains reports whether the JSON representation of `needle` is
+// contained in that of `haystack`: every key of a `needle` object must be
+// present in the corresponding `haystack` object (which may have additional
+// keys) with a contained value, arrays must have the same length and contained
+// elements, and all other values must be equal.
+func JSONContains(haystack, needle any) (bool, error) {
+	h, err := toGenericJSON(haystack)
+	if err != nil {
+		return false, err
+	}
+	n, err := toGenericJSON(needle)
+	if err != nil {
+		return false, err
+	}
+	return jsonContains(h, n), nil
+}
+
+func toGenericJSON(x any) (any, error) {
+	bs, err := json.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+
+	var generic any
+	err = dec.Decode(&generic)
+	if err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+func jsonContains(haystack, needle any) bool {
+	switch n := needle.(type) {
+	case map[string]any:
+		h, ok := haystack.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, nv := range n {
+			hv, exists := h[k]
+			if !exists || !jsonContains(hv, nv) {
+				return false
+			}
+		}
+		return true
+
+	case []any:
+		h, ok := haystack.([]any)
+		if !ok || len(h) != len(n) {
+			return false
+		}
+		for i := range n {
+			if !jsonContains(h[i], n[i]) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return haystack == needle
+	}
+}
+
+// JSONMergePatch applies an RFC 7386 JSON merge patch to `original`: objects in
+// `patch` are merged recursively, null values delete keys, and any other value
+// replaces the original outright.
+func JSONMergePatch(original, patch []byte) ([]byte, error) {
+	o, err := toGenericJSON(json.RawMessage(original))
+	if err != nil {
+		return nil, err
+	}
+	p, err := toGenericJSON(json.RawMessage(patch))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(o, p))
+}
+
+// CreateMergePatch returns an RFC 7386 JSON merge patch that transforms
+// `original` into `modified`.  Since merge patches use null to delete keys, a
+// key whose value changes to null in `modified` is deleted by the patch rather
+// than set to null.
+func CreateMergePatch(original, modified []byte) ([]byte, error) {
+	o, err := toGenericJSON(json.RawMessage(original))
+	if err != nil {
+		return nil, err
+	}
+	m, err := toGenericJSON(json.RawMessage(modified))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(createMergePatch(o, m))
+}
+
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+func createMergePatch(original, modified any) any {
+	o, ok := original.(map[string]any)
+	if !ok {
+		return modified
+	}
+	m, ok := modified.(map[string]any)
+	if !ok {
+		return modified
+	}
+
+	patch := make(map[string]any)
+	for k := range o {
+		if _, exists := m[k]; !exists {
+			patch[k] = nil
+		}
+	}
+	for k, mv := range m {
+		ov, exists := o[k]
+		if exists && reflect.DeepEqual(ov, mv) {
+			continue
+		}
+		if mv == nil {
+			patch[k] = nil
+		} else if _, isObject := ov.(map[string]any); isObject {
+			patch[k] = createMergePatch(ov, mv)
+		} else {
+			patch[k] = mv
+		}
+	}
+	return patch
+}
@@ -0,0 +1,105 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestJSONEqual(t *testing.T) {
+	type item struct {
+		B string         `json:"b"`
+		A map[string]int `json:"a"`
+	}
+
+	equal, err := utils.JSONEqual(
+		map[string]any{"a": map[string]int{"x": 1, "y": 2}, "b": "hi"},
+		item{B: "hi", A: map[string]int{"y": 2, "x": 1}},
+	)
+	require.NoError(t, err)
+	require.True(t, equal)
+
+	equal, err = utils.JSONEqual(
+		map[string]any{"a": map[string]int{"x": 1, "y": 2}, "b": "hi"},
+		item{B: "hi", A: map[string]int{"y": 3, "x": 1}},
+	)
+	require.NoError(t, err)
+	require.False(t, equal)
+
+	_, err = utils.JSONEqual(make(chan int), 1)
+	require.Error(t, err)
+}
+
+func TestJSONContains(t *testing.T) {
+	haystack := map[string]any{
+		"name": "widget",
+		"tags": []string{"a", "b"},
+		"meta": map[string]any{"color": "red", "size": 3},
+	}
+
+	contains, err := utils.JSONContains(haystack, map[string]any{"meta": map[string]any{"size": 3}})
+	require.NoError(t, err)
+	require.True(t, contains)
+
+	contains, err = utils.JSONContains(haystack, map[string]any{"name": "widget", "tags": []string{"a", "b"}})
+	require.NoError(t, err)
+	require.True(t, contains)
+
+	contains, err = utils.JSONContains(haystack, map[string]any{"meta": map[string]any{"size": 4}})
+	require.NoError(t, err)
+	require.False(t, contains)
+
+	contains, err = utils.JSONContains(haystack, map[string]any{"missing": true})
+	require.NoError(t, err)
+	require.False(t, contains)
+}
+
+func TestJSONMergePatch(t *testing.T) {
+	for _, tt := range []struct {
+		name, original, patch, expected string
+	}{
+		{"add key", `{"a":1}`, `{"b":2}`, `{"a":1,"b":2}`},
+		{"replace key", `{"a":1}`, `{"a":"x"}`, `{"a":"x"}`},
+		{"delete key via null", `{"a":1,"b":2}`, `{"b":null}`, `{"a":1}`},
+		{"delete missing key", `{"a":1}`, `{"z":null}`, `{"a":1}`},
+		{"nested merge", `{"a":{"b":1,"c":{"d":2}}}`, `{"a":{"c":{"d":null,"e":3}}}`, `{"a":{"b":1,"c":{"e":3}}}`},
+		{"arrays are replaced", `{"a":[1,2,3]}`, `{"a":[4]}`, `{"a":[4]}`},
+		{"non-object patch replaces", `{"a":1}`, `[1,2]`, `[1,2]`},
+		{"object patch on non-object", `"foo"`, `{"a":1}`, `{"a":1}`},
+		{"large numbers survive", `{"a":12345678901234567890}`, `{"b":1}`, `{"a":12345678901234567890,"b":1}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := utils.JSONMergePatch([]byte(tt.original), []byte(tt.patch))
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expected, string(got))
+		})
+	}
+
+	_, err := utils.JSONMergePatch([]byte(`{"a":`), []byte(`{}`))
+	require.Error(t, err)
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	for _, tt := range []struct {
+		name, original, modified, expected string
+	}{
+		{"no changes", `{"a":1}`, `{"a":1}`, `{}`},
+		{"added key", `{"a":1}`, `{"a":1,"b":2}`, `{"b":2}`},
+		{"deleted key", `{"a":1,"b":2}`, `{"a":1}`, `{"b":null}`},
+		{"nested", `{"a":{"b":1,"c":2},"d":[1]}`, `{"a":{"b":1,"c":3,"e":4},"d":[1]}`, `{"a":{"c":3,"e":4}}`},
+		{"object replaced by scalar", `{"a":{"b":1}}`, `{"a":5}`, `{"a":5}`},
+		{"non-object", `[1]`, `[2]`, `[2]`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := utils.CreateMergePatch([]byte(tt.original), []byte(tt.modified))
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expected, string(patch))
+
+			roundTripped, err := utils.JSONMergePatch([]byte(tt.original), patch)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.modified, string(roundTripped))
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+// NewJSONLogger creates a logger that writes JSON records with RFC3339
+// timestamps and source locations, suitable for production log collection.
+func NewJSONLogger(level slog.Leveler, w io.Writer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		AddSource:   true,
+		ReplaceAttr: formatLogTime,
+	}))
+}
+
+// NewTextLogger creates a logger that writes human-readable logfmt records,
+// intended for development.
+func NewTextLogger(level slog.Leveler, w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		AddSource:   true,
+		ReplaceAttr: formatLogTime,
+	}))
+}
+
+func formatLogTime(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.TimeKey && len(groups) == 0 && a.Value.Kind() == slog.KindTime {
+		a.Value = slog.StringValue(a.Value.Time().Format(time.RFC3339))
+	}
+	return a
+}
+
+// ParseLogLevel parses a level name ("debug", "info", "warn"/"warning",
+// "error", case-insensitive, optionally with an offset like "info+2").
+func ParseLogLevel(s string) (slog.Level, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "warning") {
+		s = "warn"
+	}
+	var level slog.Level
+	err := level.UnmarshalText([]byte(s))
+	if err != nil {
+		return 0, errors.Errorf("invalid log level %q", s)
+	}
+	return level, nil
+}
+
+type requestIDKey struct{}
+
+// RequestIDIntoContext returns a copy of `ctx` carrying the given request id.
+func RequestIDIntoContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request id carried by `ctx`, or "" if there
+// isn't one.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+type loggerKey struct{}
+
+// LoggerIntoContext returns a copy of `ctx` carrying `logger`, so that
+// middleware can enrich a logger once and handlers can retrieve it with
+// LoggerFromContext.
+func LoggerIntoContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFromContext returns the logger carried by `ctx`, or slog.Default() if
+// there isn't one.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, _ := ctx.Value(loggerKey{}).(*slog.Logger); logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+// ContextHandler is a slog.Handler that adds a `request_id` attribute (from
+// RequestIDIntoContext) to every record logged with a context, and optionally
+// a `goroutine_id` attribute.
+type ContextHandler struct {
+	slog.Handler
+	goroutineID bool
+}
+
+var _ slog.Handler = (*ContextHandler)(nil)
+
+type ContextHandlerOption func(*ContextHandler)
+
+// WithGoroutineID makes a ContextHandler add a `goroutine_id` attribute to
+// every record.  This requires capturing a stack trace per record, so it's
+// opt-in.
+func WithGoroutineID() ContextHandlerOption {
+	return func(h *ContextHandler) { h.goroutineID = true }
+}
+
+func NewContextHandler(handler slog.Handler, opts ...ContextHandlerOption) *ContextHandler {
+	h := &ContextHandler{Handler: handler}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			r.AddAttrs(slog.String("request_id", requestID))
+		}
+	}
+	if h.goroutineID {
+		r.AddAttrs(slog.Uint64("goroutine_id", goroutineID()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs), goroutineID: h.goroutineID}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name), goroutineID: h.goroutineID}
+}
+
+// goroutineID parses the current goroutine's id out of its stack trace header
+// ("goroutine 123 [running]:").
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	header := bytes.TrimPrefix(buf[:n], []byte("goroutine "))
+	idStr, _, _ := bytes.Cut(header, []byte(" "))
+	id, _ := strconv.ParseUint(string(idStr), 10, 64)
+	return id
+}
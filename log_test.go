@@ -0,0 +1,113 @@
+package utils_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestNewJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := utils.NewJSONLogger(slog.LevelInfo, &buf)
+
+	logger.Debug("filtered out")
+	logger.Info("hello", "user", 42)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+
+	var record map[string]any
+	err := json.Unmarshal([]byte(lines[0]), &record)
+	require.NoError(t, err)
+	require.Equal(t, "INFO", record["level"])
+	require.Equal(t, "hello", record["msg"])
+	require.Equal(t, float64(42), record["user"])
+	require.Contains(t, record, "source")
+
+	_, err = time.Parse(time.RFC3339, record["time"].(string))
+	require.NoError(t, err)
+}
+
+func TestNewTextLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := utils.NewTextLogger(slog.LevelWarn, &buf)
+
+	logger.Info("filtered out")
+	logger.Warn("careful")
+
+	require.NotContains(t, buf.String(), "filtered out")
+	require.Contains(t, buf.String(), "level=WARN")
+	require.Contains(t, buf.String(), "msg=careful")
+}
+
+func TestParseLogLevel(t *testing.T) {
+	for input, expected := range map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warn":    slog.LevelWarn,
+		"Warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+		"info+2":  slog.LevelInfo + 2,
+	} {
+		level, err := utils.ParseLogLevel(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, level, input)
+	}
+
+	_, err := utils.ParseLogLevel("loud")
+	require.Error(t, err)
+}
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(utils.NewContextHandler(slog.NewJSONHandler(&buf, nil)))
+
+	ctx := utils.RequestIDIntoContext(context.Background(), "req-123")
+	logger.InfoContext(ctx, "handling")
+
+	var record map[string]any
+	err := json.Unmarshal(buf.Bytes(), &record)
+	require.NoError(t, err)
+	require.Equal(t, "req-123", record["request_id"])
+	require.NotContains(t, record, "goroutine_id")
+
+	t.Run("no request id", func(t *testing.T) {
+		buf.Reset()
+		logger.Info("no context")
+		require.NotContains(t, buf.String(), "request_id")
+	})
+
+	t.Run("goroutine id is opt-in", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(utils.NewContextHandler(slog.NewJSONHandler(&buf, nil), utils.WithGoroutineID()))
+		logger.With("component", "test").InfoContext(ctx, "handling")
+
+		var record map[string]any
+		err := json.Unmarshal(buf.Bytes(), &record)
+		require.NoError(t, err)
+		require.Equal(t, "req-123", record["request_id"])
+		require.Equal(t, "test", record["component"])
+		require.Greater(t, record["goroutine_id"], float64(0))
+	})
+}
+
+func TestLoggerFromContext(t *testing.T) {
+	require.Same(t, slog.Default(), utils.LoggerFromContext(context.Background()))
+	require.Same(t, slog.Default(), utils.LoggerFromContext(utils.LoggerIntoContext(context.Background(), nil)))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil)).With("request_id", "req-123")
+	ctx := utils.LoggerIntoContext(context.Background(), logger)
+	require.Same(t, logger, utils.LoggerFromContext(ctx))
+
+	utils.LoggerFromContext(ctx).Info("handling")
+	require.Contains(t, buf.String(), `"request_id":"req-123"`)
+}
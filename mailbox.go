@@ -1,33 +1,71 @@
 package utils
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type Mailbox[T any] struct {
 	mu       sync.Mutex
+	notFull  *sync.Cond
 	chNotify chan struct{}
 	queue    []T
 	queueLen atomic.Int64 // atomic so monitor can read w/o blocking the queue
+	dropped  atomic.Uint64
 
 	// capacity - number of items the mailbox can buffer
 	// NOTE: if the capacity is 1, it's possible that an empty Retrieve may occur after a notification.
 	capacity uint64
+	overflow OverflowPolicy
+}
+
+// OverflowPolicy determines what a Mailbox does when Deliver is called while
+// it's at capacity.
+type OverflowPolicy int
+
+const (
+	// DropOldest discards the oldest queued element to make room for the new
+	// one.  This is the default.
+	DropOldest OverflowPolicy = iota
+	// DropNewest discards the element being delivered.
+	DropNewest
+	// Block makes Deliver wait until an element has been retrieved.
+	Block
+)
+
+type MailboxOption func(*mailboxOptions)
+
+type mailboxOptions struct {
+	overflow OverflowPolicy
+}
+
+// WithOverflowPolicy sets the behavior of a Mailbox when it's at capacity.
+func WithOverflowPolicy(policy OverflowPolicy) MailboxOption {
+	return func(opts *mailboxOptions) { opts.overflow = policy }
 }
 
 // Creates a new mailbox instance. If name is non-empty, it must be unique and calling Start will launch
 // prometheus metric monitor that periodically reports mailbox load until Close() is called.
-func NewMailbox[T any](capacity uint64) *Mailbox[T] {
+func NewMailbox[T any](capacity uint64, opts ...MailboxOption) *Mailbox[T] {
+	var options mailboxOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	queueCap := capacity
 	if queueCap == 0 {
 		queueCap = 100
 	}
-	return &Mailbox[T]{
+	m := &Mailbox[T]{
 		chNotify: make(chan struct{}, 1),
 		queue:    make([]T, 0, queueCap),
 		capacity: capacity,
+		overflow: options.overflow,
 	}
+	m.notFull = sync.NewCond(&m.mu)
+	return m
 }
 
 // Notify returns the contents of the notify channel
@@ -41,24 +79,49 @@ func (m *Mailbox[T]) load() (capacity uint64, loadPercent float64) {
 	return
 }
 
+// Dropped returns the number of elements that have been discarded because the
+// mailbox was at capacity.
+func (m *Mailbox[T]) Dropped() uint64 {
+	return m.dropped.Load()
+}
+
 // Deliver appends to the queue and returns true if the queue was full, causing a message to be dropped.
+// Which message is dropped depends on the mailbox's OverflowPolicy.  With the Block policy, Deliver
+// waits for room in the queue instead and always returns false.
 func (m *Mailbox[T]) Deliver(x T) (wasOverCapacity bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.queue = append([]T{x}, m.queue...)
-	if uint64(len(m.queue)) > m.capacity && m.capacity > 0 {
-		m.queue = m.queue[:len(m.queue)-1]
+	if m.overflow == Block && m.capacity > 0 {
+		for uint64(len(m.queue)) >= m.capacity {
+			m.notFull.Wait()
+		}
+	}
+
+	if m.overflow == DropNewest && m.capacity > 0 && uint64(len(m.queue)) >= m.capacity {
 		wasOverCapacity = true
+		m.dropped.Add(1)
 	} else {
-		m.queueLen.Add(1)
+		m.queue = append([]T{x}, m.queue...)
+		if uint64(len(m.queue)) > m.capacity && m.capacity > 0 {
+			m.queue = m.queue[:len(m.queue)-1]
+			wasOverCapacity = true
+			m.dropped.Add(1)
+		} else {
+			m.queueLen.Add(1)
+		}
 	}
 
+	m.notify()
+	return
+}
+
+// notify signals the notify channel without blocking.
+func (m *Mailbox[T]) notify() {
 	select {
 	case m.chNotify <- struct{}{}:
 	default:
 	}
-	return
 }
 
 // Retrieve fetches one element from the queue.
@@ -71,6 +134,7 @@ func (m *Mailbox[T]) Retrieve() (t T, ok bool) {
 	t = m.queue[len(m.queue)-1]
 	m.queue = m.queue[:len(m.queue)-1]
 	m.queueLen.Add(-1)
+	m.notFull.Broadcast()
 	ok = true
 	return
 }
@@ -82,12 +146,43 @@ func (m *Mailbox[T]) RetrieveAll() []T {
 	queue := m.queue
 	m.queue = nil
 	m.queueLen.Store(0)
+	m.notFull.Broadcast()
 	for i, j := 0, len(queue)-1; i < j; i, j = i+1, j-1 {
 		queue[i], queue[j] = queue[j], queue[i]
 	}
 	return queue
 }
 
+// RetrieveAllWait waits until at least `minItems` elements are queued (or until
+// `maxWait` elapses or `ctx` is done, whichever happens first) and then fetches
+// all elements from the queue.  It may return fewer than `minItems` elements,
+// including none.
+//
+// RetrieveAllWait waits on the same channel that Notify returns, so it can
+// consume a wakeup meant for another goroutine waiting on Notify.  It re-signals
+// the channel if elements remain queued when it returns (i.e. ones delivered
+// after it retrieved), so that other consumers aren't left waiting on them.
+func (m *Mailbox[T]) RetrieveAllWait(ctx context.Context, minItems int, maxWait time.Duration) []T {
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+
+Outer:
+	for m.queueLen.Load() < int64(minItems) {
+		select {
+		case <-m.chNotify:
+		case <-timer.C:
+			break Outer
+		case <-ctx.Done():
+			break Outer
+		}
+	}
+	items := m.RetrieveAll()
+	if m.queueLen.Load() > 0 {
+		m.notify()
+	}
+	return items
+}
+
 // RetrieveLatestAndClear fetch the latest value (or nil), and clears the rest of the queue (if any).
 func (m *Mailbox[T]) RetrieveLatestAndClear() (t T) {
 	m.mu.Lock()
@@ -98,5 +193,6 @@ func (m *Mailbox[T]) RetrieveLatestAndClear() (t T) {
 	t = m.queue[0]
 	m.queue = nil
 	m.queueLen.Store(0)
+	m.notFull.Broadcast()
 	return
 }
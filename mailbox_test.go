@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -179,3 +181,94 @@ func TestMailbox_load(t *testing.T) {
 		})
 	}
 }
+
+func TestMailbox_RetrieveAllWait(t *testing.T) {
+	t.Run("returns as soon as minItems are available", func(t *testing.T) {
+		m := NewMailbox[int](10)
+		go func() {
+			for i := 0; i < 3; i++ {
+				time.Sleep(5 * time.Millisecond)
+				m.Deliver(i)
+			}
+		}()
+
+		start := time.Now()
+		items := m.RetrieveAllWait(context.Background(), 3, 5*time.Second)
+		require.Equal(t, []int{0, 1, 2}, items)
+		require.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("returns fewer items after maxWait", func(t *testing.T) {
+		m := NewMailbox[int](10)
+		m.Deliver(1)
+
+		start := time.Now()
+		items := m.RetrieveAllWait(context.Background(), 5, 20*time.Millisecond)
+		require.Equal(t, []int{1}, items)
+		require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+
+	t.Run("returns when the context is cancelled", func(t *testing.T) {
+		m := NewMailbox[int](10)
+		m.Deliver(1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+
+		start := time.Now()
+		items := m.RetrieveAllWait(ctx, 5, 5*time.Second)
+		require.Equal(t, []int{1}, items)
+		require.Less(t, time.Since(start), 5*time.Second)
+	})
+}
+
+func TestMailbox_OverflowPolicy(t *testing.T) {
+	t.Run("DropOldest", func(t *testing.T) {
+		m := NewMailbox[int](3)
+		for i := 0; i < 5; i++ {
+			m.Deliver(i)
+		}
+		require.Equal(t, []int{2, 3, 4}, m.RetrieveAll())
+		require.Equal(t, uint64(2), m.Dropped())
+	})
+
+	t.Run("DropNewest", func(t *testing.T) {
+		m := NewMailbox[int](3, WithOverflowPolicy(DropNewest))
+		for i := 0; i < 5; i++ {
+			wasOverCapacity := m.Deliver(i)
+			require.Equal(t, i >= 3, wasOverCapacity)
+		}
+		require.Equal(t, []int{0, 1, 2}, m.RetrieveAll())
+		require.Equal(t, uint64(2), m.Dropped())
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		m := NewMailbox[int](3, WithOverflowPolicy(Block))
+		for i := 0; i < 3; i++ {
+			require.False(t, m.Deliver(i))
+		}
+
+		delivered := make(chan struct{})
+		go func() {
+			defer close(delivered)
+			m.Deliver(3)
+		}()
+
+		select {
+		case <-delivered:
+			t.Fatal("Deliver should block while the mailbox is full")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		x, ok := m.Retrieve()
+		require.True(t, ok)
+		require.Equal(t, 0, x)
+		<-delivered
+
+		require.Equal(t, []int{1, 2, 3}, m.RetrieveAll())
+		require.Equal(t, uint64(0), m.Dropped())
+	})
+}
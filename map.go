@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Keys returns the keys of `m` in unspecified order.
+func Keys[K comparable, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// SortedKeys returns the keys of `m` in ascending order.
+func SortedKeys[K cmp.Ordered, V any](m map[K]V) []K {
+	keys := Keys(m)
+	slices.Sort(keys)
+	return keys
+}
+
+// Values returns the values of `m` in unspecified order.
+func Values[K comparable, V any](m map[K]V) []V {
+	values := make([]V, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
+
+// SortedValues returns the values of `m` ordered by their keys.
+func SortedValues[K cmp.Ordered, V any](m map[K]V) []V {
+	values := make([]V, 0, len(m))
+	for _, k := range SortedKeys(m) {
+		values = append(values, m[k])
+	}
+	return values
+}
+
+type Entry[K comparable, V any] struct {
+	K K
+	V V
+}
+
+// Entries returns the key/value pairs of `m` in unspecified order.
+func Entries[K comparable, V any](m map[K]V) []Entry[K, V] {
+	entries := make([]Entry[K, V], 0, len(m))
+	for k, v := range m {
+		entries = append(entries, Entry[K, V]{k, v})
+	}
+	return entries
+}
+
+// SortedEntries returns the key/value pairs of `m` ordered by their keys.
+func SortedEntries[K cmp.Ordered, V any](m map[K]V) []Entry[K, V] {
+	entries := make([]Entry[K, V], 0, len(m))
+	for _, k := range SortedKeys(m) {
+		entries = append(entries, Entry[K, V]{k, m[k]})
+	}
+	return entries
+}
@@ -0,0 +1,33 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestMapHelpers(t *testing.T) {
+	t.Run("empty maps", func(t *testing.T) {
+		var m map[string]int
+		require.Empty(t, utils.Keys(m))
+		require.Empty(t, utils.Values(m))
+		require.Empty(t, utils.Entries(m))
+		require.Empty(t, utils.SortedKeys(m))
+	})
+
+	m := map[string]int{"c": 3, "a": 1, "b": 2}
+
+	t.Run("unsorted", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"a", "b", "c"}, utils.Keys(m))
+		require.ElementsMatch(t, []int{1, 2, 3}, utils.Values(m))
+		require.ElementsMatch(t, []utils.Entry[string, int]{{"a", 1}, {"b", 2}, {"c", 3}}, utils.Entries(m))
+	})
+
+	t.Run("sorted", func(t *testing.T) {
+		require.Equal(t, []string{"a", "b", "c"}, utils.SortedKeys(m))
+		require.Equal(t, []int{1, 2, 3}, utils.SortedValues(m))
+		require.Equal(t, []utils.Entry[string, int]{{"a", 1}, {"b", 2}, {"c", 3}}, utils.SortedEntries(m))
+	})
+}
@@ -1,5 +1,13 @@
 package utils
 
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/brynbellomy/go-utils/errors"
+)
+
 func PtrTo[T any](x T) *T {
 	return &x
 }
@@ -11,3 +19,108 @@ func ZeroIfNil[T any](x *T) T {
 	}
 	return *x
 }
+
+// Must returns `v`, or panics with `err` (annotated with a stack trace) if it's
+// non-nil, e.g. `db := utils.Must(sqlx.Connect("postgres", uri))`.
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(errors.WithStack(err))
+	}
+	return v
+}
+
+// Must0 is like Must, for calls that only return an error.
+func Must0(err error) {
+	if err != nil {
+		panic(errors.WithStack(err))
+	}
+}
+
+// AssertionError is the panic value (wrapped with a stack trace) of a failed
+// Assert or Invariant.
+type AssertionError struct {
+	Message string
+	Fields  []any // alternating keys and values, as with slog
+}
+
+func (e *AssertionError) Error() string {
+	if len(e.Fields) == 0 {
+		return "assertion failed: " + e.Message
+	}
+	var sb strings.Builder
+	for i := 0; i < len(e.Fields); i += 2 {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		if i+1 < len(e.Fields) {
+			fmt.Fprintf(&sb, "%v=%v", e.Fields[i], e.Fields[i+1])
+		} else {
+			fmt.Fprintf(&sb, "%v", e.Fields[i])
+		}
+	}
+	return fmt.Sprintf("assertion failed: %v (%v)", e.Message, sb.String())
+}
+
+// Assert panics with an *AssertionError carrying `msg` and `fields`
+// (alternating keys and values) if `cond` is false.
+func Assert(cond bool, msg string, fields ...any) {
+	if !cond {
+		panic(errors.WithStack(&AssertionError{Message: msg, Fields: fields}))
+	}
+}
+
+// Invariant is like Assert, with a formatted message.  The message is only
+// formatted if `cond` is false.
+func Invariant(cond bool, format string, args ...any) {
+	if !cond {
+		panic(errors.WithStack(&AssertionError{Message: fmt.Sprintf(format, args...)}))
+	}
+}
+
+// PanicError is the error (wrapped with a stack trace from the panic site)
+// returned by SafeCall when `fn` panics.
+type PanicError struct {
+	Value any
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// SafeCall calls `fn`, converting a panic into a *PanicError.
+func SafeCall[T any](fn func() (T, error)) (_ T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.WithStack(&PanicError{Value: r})
+		}
+	}()
+	return fn()
+}
+
+// SafeRetry calls `fn` via SafeCall up to `maxAttempts` times, waiting on
+// `backoff` between attempts, until it succeeds.  Panics are retried like any
+// other error.  The last attempt's error is returned, or the context's error if
+// `ctx` is done while waiting.  `backoff` is reset before the first attempt, so
+// it can be reused across calls (but not shared by concurrent calls).
+func SafeRetry[T any](ctx context.Context, backoff *ResettableBackoff, maxAttempts int, fn func() (T, error)) (T, error) {
+	var (
+		x   T
+		err error
+	)
+	if maxAttempts < 1 {
+		return x, errors.Errorf("SafeRetry: maxAttempts must be at least 1, got %d", maxAttempts)
+	}
+	backoff.Reset()
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		x, err = SafeCall(fn)
+		if err == nil {
+			return x, nil
+		} else if attempt == maxAttempts {
+			break
+		}
+		if waitErr := backoff.Wait(ctx); waitErr != nil {
+			return x, waitErr
+		}
+	}
+	return x, errors.Wrapf(err, "giving up after %d attempts", maxAttempts)
+}
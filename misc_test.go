@@ -0,0 +1,141 @@
+package utils_test
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+func TestMust(t *testing.T) {
+	require.Equal(t, 42, utils.Must(strconv.Atoi("42")))
+	require.NotPanics(t, func() { utils.Must0(nil) })
+
+	recovered := func(fn func()) (err error) {
+		defer func() { err = recover().(error) }()
+		fn()
+		return nil
+	}
+
+	errBoom := stderrors.New("boom")
+	for name, fn := range map[string]func(){
+		"Must":  func() { utils.Must(0, errBoom) },
+		"Must0": func() { utils.Must0(errBoom) },
+	} {
+		err := recovered(fn)
+		require.ErrorIs(t, err, errBoom, name)
+		require.Equal(t, errBoom, errors.Cause(err), name)
+		require.Contains(t, fmt.Sprintf("%+v", err), "TestMust", name)
+	}
+}
+
+func TestAssert(t *testing.T) {
+	require.NotPanics(t, func() { utils.Assert(true, "fine", "k", "v") })
+	require.NotPanics(t, func() { utils.Invariant(true, "fine %d", 1) })
+
+	recovered := func(fn func()) (err error) {
+		defer func() { err = recover().(error) }()
+		fn()
+		return nil
+	}
+
+	err := recovered(func() { utils.Assert(false, "queue overflow", "len", 11, "cap", 10) })
+	var assertionErr *utils.AssertionError
+	require.True(t, errors.As(err, &assertionErr))
+	require.Equal(t, "queue overflow", assertionErr.Message)
+	require.Equal(t, []any{"len", 11, "cap", 10}, assertionErr.Fields)
+	require.Equal(t, "assertion failed: queue overflow (len=11 cap=10)", assertionErr.Error())
+	require.Contains(t, fmt.Sprintf("%+v", err), "TestAssert")
+
+	err = recovered(func() { utils.Invariant(false, "index %d out of range [0, %d)", 5, 3) })
+	require.True(t, errors.As(err, &assertionErr))
+	require.Equal(t, "assertion failed: index 5 out of range [0, 3)", assertionErr.Error())
+}
+
+func TestSafeCall(t *testing.T) {
+	x, err := utils.SafeCall(func() (int, error) { return 1, nil })
+	require.NoError(t, err)
+	require.Equal(t, 1, x)
+
+	_, err = utils.SafeCall(func() (int, error) { panic("boom") })
+	var panicErr *utils.PanicError
+	require.True(t, errors.As(err, &panicErr))
+	require.Equal(t, "boom", panicErr.Value)
+	require.Contains(t, fmt.Sprintf("%+v", err), "TestSafeCall")
+}
+
+func TestSafeRetry(t *testing.T) {
+	t.Run("recovers from a panic on the first call", func(t *testing.T) {
+		var calls int
+		x, err := utils.SafeRetry(context.Background(), utils.NewResettableBackoff(time.Millisecond, 10*time.Millisecond), 3, func() (string, error) {
+			calls++
+			if calls == 1 {
+				panic("flaky")
+			}
+			return "ok", nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, "ok", x)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("gives up after maxAttempts", func(t *testing.T) {
+		var calls int
+		_, err := utils.SafeRetry(context.Background(), utils.NewResettableBackoff(time.Millisecond, 10*time.Millisecond), 3, func() (string, error) {
+			calls++
+			panic("always")
+		})
+		require.Equal(t, 3, calls)
+		var panicErr *utils.PanicError
+		require.True(t, errors.As(err, &panicErr))
+		require.Equal(t, "always", panicErr.Value)
+	})
+
+	t.Run("rejects maxAttempts < 1", func(t *testing.T) {
+		var calls int
+		_, err := utils.SafeRetry(context.Background(), utils.NewResettableBackoff(time.Millisecond, time.Millisecond), 0, func() (string, error) {
+			calls++
+			return "ok", nil
+		})
+		require.Error(t, err)
+		require.Equal(t, 0, calls)
+	})
+
+	t.Run("resets the backoff before the first attempt", func(t *testing.T) {
+		backoff := utils.NewResettableBackoff(time.Millisecond, time.Hour)
+		for i := 0; i < 20; i++ {
+			backoff.NextDelay()
+		}
+
+		var calls int
+		start := time.Now()
+		_, err := utils.SafeRetry(context.Background(), backoff, 2, func() (string, error) {
+			calls++
+			if calls == 1 {
+				return "", errors.New("flaky")
+			}
+			return "ok", nil
+		})
+		require.NoError(t, err)
+		require.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var calls int
+		_, err := utils.SafeRetry(ctx, utils.NewResettableBackoff(time.Hour, time.Hour), 3, func() (string, error) {
+			calls++
+			return "", errors.New("nope")
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 1, calls)
+	})
+}
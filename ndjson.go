@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"iter"
+	"sync"
+
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+// NDJSONEncoder writes values as newline-delimited JSON (one object per line).
+// It's safe for concurrent use.
+type NDJSONEncoder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewNDJSONEncoder(w io.Writer) *NDJSONEncoder {
+	return &NDJSONEncoder{enc: json.NewEncoder(w)}
+}
+
+func (e *NDJSONEncoder) Encode(v any) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.enc.Encode(v)
+}
+
+// NDJSONDecoder reads newline-delimited JSON.  Blank lines are skipped.  By
+// default, a malformed line yields an error and decoding continues with the
+// next line; use NDJSONStopOnError to end the sequence at the first error
+// instead.  Errors reading from the underlying reader always end the sequence.
+type NDJSONDecoder struct {
+	r           *bufio.Reader
+	stopOnError bool
+}
+
+type NDJSONDecoderOption func(d *NDJSONDecoder)
+
+// NDJSONStopOnError ends the decoded sequence after the first malformed line.
+func NDJSONStopOnError() NDJSONDecoderOption {
+	return func(d *NDJSONDecoder) { d.stopOnError = true }
+}
+
+func NewNDJSONDecoder(r io.Reader, opts ...NDJSONDecoderOption) *NDJSONDecoder {
+	d := &NDJSONDecoder{r: bufio.NewReader(r)}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Records returns a sequence of the raw JSON values in the stream, one per
+// non-blank line.
+func (d *NDJSONDecoder) Records() iter.Seq2[json.RawMessage, error] {
+	return func(yield func(json.RawMessage, error) bool) {
+		for lineNum := 1; ; lineNum++ {
+			line, err := d.r.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				yield(nil, errors.Wrapf(err, "ndjson: reading line %d", lineNum))
+				return
+			}
+			atEOF := err == io.EOF
+
+			line = bytes.TrimSpace(line)
+			if len(line) > 0 {
+				if !json.Valid(line) {
+					if !yield(nil, errors.Errorf("ndjson: malformed JSON on line %d", lineNum)) || d.stopOnError {
+						return
+					}
+				} else if !yield(json.RawMessage(line), nil) {
+					return
+				}
+			}
+			if atEOF {
+				return
+			}
+		}
+	}
+}
+
+// DecodeNDJSON returns a sequence of the values in the stream, each
+// unmarshaled into a T.  Values that fail to unmarshal are treated like
+// malformed lines.
+func DecodeNDJSON[T any](d *NDJSONDecoder) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for raw, err := range d.Records() {
+			var x T
+			if err == nil {
+				err = json.Unmarshal(raw, &x)
+				if err != nil {
+					err = errors.Wrap(err, "ndjson")
+				}
+			}
+			if !yield(x, err) || (err != nil && d.stopOnError) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package utils_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+type ndjsonRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestNDJSON(t *testing.T) {
+	records := []ndjsonRecord{{1, "a"}, {2, "b\nc"}, {3, "d"}}
+
+	var buf bytes.Buffer
+	enc := utils.NewNDJSONEncoder(&buf)
+	for _, r := range records {
+		require.NoError(t, enc.Encode(r))
+	}
+	require.Equal(t, 3, strings.Count(buf.String(), "\n"))
+
+	t.Run("raw records round-trip", func(t *testing.T) {
+		var got []ndjsonRecord
+		for raw, err := range utils.NewNDJSONDecoder(bytes.NewReader(buf.Bytes())).Records() {
+			require.NoError(t, err)
+			var r ndjsonRecord
+			require.NoError(t, json.Unmarshal(raw, &r))
+			got = append(got, r)
+		}
+		require.Equal(t, records, got)
+	})
+
+	t.Run("typed decode skips blank lines", func(t *testing.T) {
+		input := "\n" + strings.ReplaceAll(buf.String(), "\n", "\n  \n") + `{"id":4,"name":"e"}`
+
+		var got []ndjsonRecord
+		for r, err := range utils.DecodeNDJSON[ndjsonRecord](utils.NewNDJSONDecoder(strings.NewReader(input))) {
+			require.NoError(t, err)
+			got = append(got, r)
+		}
+		require.Equal(t, append(records, ndjsonRecord{4, "e"}), got)
+	})
+
+	input := `{"id":1,"name":"a"}` + "\n" + `{"id":2,` + "\n" + `{"id":"three"}` + "\n" + `{"id":4,"name":"d"}` + "\n"
+
+	t.Run("malformed lines don't abort decoding", func(t *testing.T) {
+		var got []ndjsonRecord
+		var errs []error
+		for r, err := range utils.DecodeNDJSON[ndjsonRecord](utils.NewNDJSONDecoder(strings.NewReader(input))) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			got = append(got, r)
+		}
+		require.Equal(t, []ndjsonRecord{{1, "a"}, {4, "d"}}, got)
+		require.Len(t, errs, 2)
+		require.Contains(t, errs[0].Error(), "line 2")
+	})
+
+	t.Run("NDJSONStopOnError", func(t *testing.T) {
+		var got []ndjsonRecord
+		var errs []error
+		for r, err := range utils.DecodeNDJSON[ndjsonRecord](utils.NewNDJSONDecoder(strings.NewReader(input), utils.NDJSONStopOnError())) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			got = append(got, r)
+		}
+		require.Equal(t, []ndjsonRecord{{1, "a"}}, got)
+		require.Len(t, errs, 1)
+	})
+}
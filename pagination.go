@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/brynbellomy/go-utils/errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor encodes `v` as an opaque, URL-safe pagination cursor (base64 of
+// its JSON representation).
+func EncodeCursor[T any](v T) (string, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bs), nil
+}
+
+// DecodeCursor decodes a cursor produced by EncodeCursor.  Malformed cursors
+// return an error wrapping ErrInvalidCursor.
+func DecodeCursor[T any](s string) (T, error) {
+	var v T
+	bs, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return v, errors.Wrap(ErrInvalidCursor, err.Error())
+	}
+	err = json.Unmarshal(bs, &v)
+	if err != nil {
+		return v, errors.Wrap(ErrInvalidCursor, err.Error())
+	}
+	return v, nil
+}
+
+// Page is one page of results from Paginate.  NextCursor is empty on the last
+// page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Paginate builds a Page from `results`, which should have been fetched with a
+// limit of `limit+1` so that the presence of an extra row signals that another
+// page follows.  In that case the extra row is dropped, and NextCursor encodes
+// `key` of the last item returned.  A negative `limit` is an error.
+func Paginate[T, C any](results []T, limit int, key func(T) C) (Page[T], error) {
+	if limit < 0 {
+		return Page[T]{}, errors.Errorf("invalid page limit %d", limit)
+	}
+	if len(results) <= limit {
+		return Page[T]{Items: results}, nil
+	}
+
+	items := results[:limit]
+	if len(items) == 0 {
+		return Page[T]{Items: items}, nil
+	}
+	cursor, err := EncodeCursor(key(items[len(items)-1]))
+	if err != nil {
+		return Page[T]{}, err
+	}
+	return Page[T]{Items: items, NextCursor: cursor}, nil
+}
@@ -0,0 +1,66 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+type cursorKey struct {
+	CreatedAt time.Time `json:"createdAt"`
+	ID        int       `json:"id"`
+}
+
+func TestCursor(t *testing.T) {
+	key := cursorKey{CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), ID: 42}
+
+	cursor, err := utils.EncodeCursor(key)
+	require.NoError(t, err)
+	require.NotContains(t, cursor, "=")
+
+	decoded, err := utils.DecodeCursor[cursorKey](cursor)
+	require.NoError(t, err)
+	require.Equal(t, key, decoded)
+
+	_, err = utils.DecodeCursor[cursorKey]("not a cursor!")
+	require.ErrorIs(t, err, utils.ErrInvalidCursor)
+
+	notJSON, err := utils.EncodeCursor("just a string")
+	require.NoError(t, err)
+	_, err = utils.DecodeCursor[cursorKey](notJSON)
+	require.ErrorIs(t, err, utils.ErrInvalidCursor)
+}
+
+func TestPaginate(t *testing.T) {
+	type row struct{ ID int }
+	key := func(r row) int { return r.ID }
+
+	t.Run("more results follow", func(t *testing.T) {
+		page, err := utils.Paginate([]row{{1}, {2}, {3}}, 2, key)
+		require.NoError(t, err)
+		require.Equal(t, []row{{1}, {2}}, page.Items)
+
+		next, err := utils.DecodeCursor[int](page.NextCursor)
+		require.NoError(t, err)
+		require.Equal(t, 2, next)
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		page, err := utils.Paginate([]row{{1}, {2}}, 2, key)
+		require.NoError(t, err)
+		require.Equal(t, []row{{1}, {2}}, page.Items)
+		require.Empty(t, page.NextCursor)
+
+		page, err = utils.Paginate([]row(nil), 2, key)
+		require.NoError(t, err)
+		require.Empty(t, page.Items)
+		require.Empty(t, page.NextCursor)
+	})
+	t.Run("negative limit", func(t *testing.T) {
+		_, err := utils.Paginate([]row{{1}, {2}}, -1, key)
+		require.Error(t, err)
+	})
+}
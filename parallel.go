@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+)
+
+// MapReduce applies `mapFn` to each of `items` using up to `concurrency`
+// goroutines, and folds the results into `init` with `reduceFn`.  `reduceFn`
+// is only ever called from the calling goroutine, so it doesn't need to be
+// thread-safe, but it sees results in completion order rather than input order.
+// The first error returned by `mapFn` cancels the remaining work and is
+// returned along with the partially-reduced value.
+func MapReduce[In, Mid, Out any](
+	ctx context.Context,
+	concurrency int,
+	items []In,
+	mapFn func(context.Context, In) (Mid, error),
+	reduceFn func(Out, Mid) Out,
+	init Out,
+) (Out, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		mid Mid
+		err error
+	}
+
+	chItems := make(chan In)
+	chResults := make(chan result)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range chItems {
+				mid, err := mapFn(ctx, item)
+				select {
+				case chResults <- result{mid, err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(chItems)
+		for _, item := range items {
+			select {
+			case chItems <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(chResults)
+	}()
+
+	out := init
+	for i := 0; i < len(items); i++ {
+		select {
+		case res, open := <-chResults:
+			if !open {
+				return out, ctx.Err()
+			} else if res.err != nil {
+				cancel()
+				wg.Wait()
+				return out, res.err
+			}
+			out = reduceFn(out, res.mid)
+		case <-ctx.Done():
+			wg.Wait()
+			return out, ctx.Err()
+		}
+	}
+	return out, nil
+}
+
+type chunkOptions struct {
+	stopOnFirstError bool
+}
+
+type ProcessChunksOption func(*chunkOptions)
+
+// StopOnFirstError makes ProcessChunks cancel the remaining chunks and return
+// as soon as one chunk fails, rather than processing every chunk and joining
+// the errors.
+func StopOnFirstError() ProcessChunksOption {
+	return func(opts *chunkOptions) { opts.stopOnFirstError = true }
+}
+
+// ProcessChunks splits `items` into chunks of up to `chunkSize` and calls `fn`
+// on each chunk, running up to `concurrency` calls at once.  By default, every
+// chunk is processed and the errors from all failed chunks are returned joined
+// with `errors.Join`.
+func ProcessChunks[T any](ctx context.Context, items []T, chunkSize, concurrency int, fn func(context.Context, []T) error, opts ...ProcessChunksOption) error {
+	var options chunkOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		errs  []error
+		chSem = make(chan struct{}, concurrency)
+	)
+
+Outer:
+	for start := 0; start < len(items); start += chunkSize {
+		end := min(start+chunkSize, len(items))
+
+		select {
+		case chSem <- struct{}{}:
+		case <-ctx.Done():
+			break Outer
+		}
+
+		wg.Add(1)
+		go func(chunk []T) {
+			defer wg.Done()
+			defer func() { <-chSem }()
+
+			err := fn(ctx, chunk)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+				if options.stopOnFirstError {
+					cancel()
+				}
+			}
+		}(items[start:end:end])
+	}
+	wg.Wait()
+
+	if options.stopOnFirstError && len(errs) > 0 {
+		return errs[0]
+	} else if len(errs) == 0 && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return stderrors.Join(errs...)
+}
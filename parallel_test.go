@@ -0,0 +1,140 @@
+package utils_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+	"github.com/brynbellomy/go-utils/errors"
+	"github.com/brynbellomy/go-utils/fn"
+)
+
+func TestMapReduce(t *testing.T) {
+	t.Run("parallel sum", func(t *testing.T) {
+		items := fn.Range(1, 101)
+
+		var reducing atomic.Int32
+		var concurrentReduces atomic.Int32
+		sum, err := utils.MapReduce(context.Background(), 8, items,
+			func(ctx context.Context, x int) (int, error) {
+				time.Sleep(time.Millisecond)
+				return x * 2, nil
+			},
+			func(acc int, x int) int {
+				if reducing.Add(1) > 1 {
+					concurrentReduces.Add(1)
+				}
+				defer reducing.Add(-1)
+				return acc + x
+			},
+			0,
+		)
+		require.NoError(t, err)
+		require.Equal(t, 10100, sum)
+		require.Equal(t, int32(0), concurrentReduces.Load())
+	})
+
+	t.Run("short-circuits on the first error", func(t *testing.T) {
+		items := fn.Range(0, 1000)
+
+		var mapped atomic.Int32
+		_, err := utils.MapReduce(context.Background(), 4, items,
+			func(ctx context.Context, x int) (int, error) {
+				mapped.Add(1)
+				if x == 10 {
+					return 0, errors.ErrBadRequest
+				}
+				return x, nil
+			},
+			func(acc int, x int) int { return acc + x },
+			0,
+		)
+		require.ErrorIs(t, err, errors.ErrBadRequest)
+		require.Less(t, mapped.Load(), int32(1000))
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		out, err := utils.MapReduce(context.Background(), 4, []int(nil),
+			func(ctx context.Context, x int) (int, error) { return x, nil },
+			func(acc int, x int) int { return acc + x },
+			42,
+		)
+		require.NoError(t, err)
+		require.Equal(t, 42, out)
+	})
+}
+
+func TestProcessChunks(t *testing.T) {
+	collect := func(t *testing.T, items []int, chunkSize int) [][]int {
+		var mu sync.Mutex
+		var chunks [][]int
+		err := utils.ProcessChunks(context.Background(), items, chunkSize, 2, func(ctx context.Context, chunk []int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			chunks = append(chunks, chunk)
+			return nil
+		})
+		require.NoError(t, err)
+		sort.Slice(chunks, func(i, j int) bool { return chunks[i][0] < chunks[j][0] })
+		return chunks
+	}
+
+	t.Run("exact chunking", func(t *testing.T) {
+		require.Equal(t, [][]int{{0, 1, 2}, {3, 4, 5}}, collect(t, fn.Range(0, 6), 3))
+	})
+
+	t.Run("uneven chunking", func(t *testing.T) {
+		require.Equal(t, [][]int{{0, 1, 2, 3}, {4, 5, 6, 7}, {8, 9}}, collect(t, fn.Range(0, 10), 4))
+	})
+
+	t.Run("concurrency is bounded", func(t *testing.T) {
+		var running, maxRunning atomic.Int32
+		err := utils.ProcessChunks(context.Background(), fn.Range(0, 100), 5, 3, func(ctx context.Context, chunk []int) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				max := maxRunning.Load()
+				if n <= max || maxRunning.CompareAndSwap(max, n) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			return nil
+		})
+		require.NoError(t, err)
+		require.LessOrEqual(t, maxRunning.Load(), int32(3))
+	})
+
+	t.Run("errors are aggregated", func(t *testing.T) {
+		err := utils.ProcessChunks(context.Background(), fn.Range(0, 10), 2, 2, func(ctx context.Context, chunk []int) error {
+			switch chunk[0] {
+			case 2:
+				return errors.ErrBadRequest
+			case 6:
+				return errors.ErrNotFound
+			}
+			return nil
+		})
+		require.ErrorIs(t, err, errors.ErrBadRequest)
+		require.ErrorIs(t, err, errors.ErrNotFound)
+	})
+
+	t.Run("short-circuits when configured", func(t *testing.T) {
+		var processed atomic.Int32
+		err := utils.ProcessChunks(context.Background(), fn.Range(0, 1000), 1, 1, func(ctx context.Context, chunk []int) error {
+			processed.Add(1)
+			if chunk[0] == 3 {
+				return errors.ErrBadRequest
+			}
+			return nil
+		}, utils.StopOnFirstError())
+		require.Equal(t, errors.ErrBadRequest, err)
+		require.Less(t, processed.Load(), int32(1000))
+	})
+}
@@ -2,15 +2,21 @@ package utils
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
 	"net/url"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
+
+	"github.com/brynbellomy/go-utils/errors"
 )
 
 func PostgresURLToConnectionString(pgurl string) (string, error) {
@@ -28,59 +34,186 @@ func PostgresURLToConnectionString(pgurl string) (string, error) {
 	return fmt.Sprintf("user=%v password=%v host=%v dbname=%v sslmode=require", username, password, host, dbname), nil
 }
 
+// maxPostgresIdentifierLength is NAMEDATALEN-1 in a default Postgres build.
+// Longer identifiers are silently truncated by the server.
+const maxPostgresIdentifierLength = 63
+
+// QuotePostgresIdentifier validates `name` as a Postgres identifier and returns
+// it double-quoted (with any embedded double quotes doubled), safe for
+// interpolating into SQL.  Empty names, names containing null bytes or invalid
+// UTF-8, and names longer than 63 bytes are rejected.
+func QuotePostgresIdentifier(name string) (string, error) {
+	switch {
+	case name == "":
+		return "", errors.New("postgres identifier is empty")
+	case strings.ContainsRune(name, 0):
+		return "", errors.Errorf("postgres identifier %q contains a null byte", name)
+	case !utf8.ValidString(name):
+		return "", errors.Errorf("postgres identifier %q is not valid UTF-8", name)
+	case len(name) > maxPostgresIdentifierLength:
+		return "", errors.Errorf("postgres identifier %q is longer than %d bytes", name, maxPostgresIdentifierLength)
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`, nil
+}
+
+// pqListener is the subset of *pq.Listener used by
+// PostgresNotificationListener, so that tests can substitute a fake.
+type pqListener interface {
+	Listen(channel string) error
+	Ping() error
+	NotificationChannel() <-chan *pq.Notification
+	Close() error
+}
+
+var _ pqListener = (*pq.Listener)(nil)
+
+// PostgresNotificationListener delivers the payloads of Postgres NOTIFY
+// messages on a channel.  It pings the connection every ping interval, and on
+// failure retries with a backoff that grows from `minReconn` to `maxReconn`.
+// After several consecutive failures it tears down the connection and
+// reconnects.  A successful ping resets the backoff.
 type PostgresNotificationListener struct {
-	postgresURI string
-	listener    *pq.Listener
-	minReconn   time.Duration
-	maxReconn   time.Duration
-	mbNotifs    *Mailbox[string]
-	chStop      chan struct{}
-	wgDone      sync.WaitGroup
-	closeOnce   sync.Once
+	postgresURI     string
+	listener        pqListener // owned by the Listen goroutine once it starts
+	newListener     func() pqListener
+	minReconn       time.Duration
+	maxReconn       time.Duration
+	pingInterval    time.Duration
+	maxPingFailures int
+	clock           Clock
+	connected       atomic.Bool
+	mbNotifs        *Mailbox[string]
+	chNotifs        chan string
+	channelOnce     sync.Once
+	chStop          chan struct{}
+	wgDone          sync.WaitGroup
+	closeOnce       sync.Once
+	closeErr        error
 }
 
-func NewPostgresNotificationListener(postgresURI string, minReconn time.Duration, maxReconn time.Duration) *PostgresNotificationListener {
-	return &PostgresNotificationListener{
-		mbNotifs:    NewMailbox[string](1000),
-		postgresURI: postgresURI,
-		minReconn:   minReconn,
-		maxReconn:   maxReconn,
-		chStop:      make(chan struct{}),
+type PostgresListenerOption func(l *PostgresNotificationListener)
+
+// WithPingInterval sets how often the listener pings its connection.  The
+// default is 15 seconds.
+func WithPingInterval(d time.Duration) PostgresListenerOption {
+	return func(l *PostgresNotificationListener) { l.pingInterval = d }
+}
+
+// WithMaxPingFailures sets how many consecutive failed pings cause the listener
+// to reconnect.  The default is 3.
+func WithMaxPingFailures(n int) PostgresListenerOption {
+	return func(l *PostgresNotificationListener) { l.maxPingFailures = n }
+}
+
+func NewPostgresNotificationListener(postgresURI string, minReconn time.Duration, maxReconn time.Duration, opts ...PostgresListenerOption) *PostgresNotificationListener {
+	l := &PostgresNotificationListener{
+		mbNotifs:        NewMailbox[string](1000),
+		postgresURI:     postgresURI,
+		minReconn:       minReconn,
+		maxReconn:       maxReconn,
+		pingInterval:    15 * time.Second,
+		maxPingFailures: 3,
+		clock:           RealClock{},
+		chStop:          make(chan struct{}),
+	}
+	l.newListener = func() pqListener {
+		return pq.NewListener(l.postgresURI, l.minReconn, l.maxReconn, l.handleMetaEvent)
 	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
 }
 
 func (l *PostgresNotificationListener) Listen(channel string) error {
-	l.listener = pq.NewListener(l.postgresURI, l.minReconn, l.maxReconn, l.handleMetaEvent)
-	err := l.listener.Listen(channel)
+	// pq quotes the channel name itself, so this only validates it
+	_, err := QuotePostgresIdentifier(channel)
+	if err != nil {
+		return err
+	}
+
+	l.listener = l.newListener()
+	err = l.listener.Listen(channel)
 	if err != nil {
 		slog.Error("failed to listen to postgres channel", "channel", channel, "err", err)
+		l.listener.Close()
+		l.listener = nil
 		return err
 	}
+	l.connected.Store(true)
 
-	pingTicker := time.NewTicker(15 * time.Second)
+	pingTicker := l.clock.NewTicker(l.pingInterval)
+	pingBackoff := NewResettableBackoff(l.minReconn, l.maxReconn)
 
 	l.wgDone.Add(1)
 	go func() {
 		defer l.wgDone.Done()
 		defer pingTicker.Stop()
+		defer func() {
+			l.connected.Store(false)
+			if l.listener != nil {
+				l.closeErr = l.listener.Close()
+			}
+		}()
+
+		// While pings are failing, retry on the backoff schedule rather than
+		// waiting for the next tick.
+		var failures int
+		var chRetry <-chan time.Time
+		retry := func() {
+			l.connected.Store(false)
+			chRetry = l.clock.After(pingBackoff.NextDelay())
+		}
+
+		check := func() {
+			if l.listener == nil || failures >= l.maxPingFailures {
+				err := l.reconnect(channel)
+				if err != nil {
+					slog.Error("postgres listener reconnect failed", "channel", channel, "err", err)
+					retry()
+					return
+				}
+				failures = 0
+			}
+
+			err := l.listener.Ping()
+			if err != nil {
+				failures++
+				slog.Error("postgres listener ping failed", "channel", channel, "err", err, "failures", failures)
+				retry()
+				return
+			}
+			failures = 0
+			pingBackoff.Reset()
+			chRetry = nil
+			l.connected.Store(true)
+		}
 
-	Outer:
 		for {
+			var chNotifs <-chan *pq.Notification
+			if l.listener != nil {
+				chNotifs = l.listener.NotificationChannel()
+			}
+
 			select {
 			case <-l.chStop:
 				return
 
-			case <-pingTicker.C:
-				err := l.listener.Ping()
-				if err != nil {
-					slog.Error("postgres listener ping failed", "channel", channel)
-					continue Outer
+			case <-pingTicker.C():
+				if chRetry == nil {
+					check()
 				}
 
-			case notif, open := <-l.listener.NotificationChannel():
+			case <-chRetry:
+				check()
+
+			case notif, open := <-chNotifs:
 				if !open {
 					slog.Warn("postgres listener channel closed", "channel", channel)
 					return
+				} else if notif == nil {
+					// pq sends nil after re-establishing a dropped connection
+					continue
 				}
 				l.mbNotifs.Deliver(notif.Extra)
 			}
@@ -89,20 +222,46 @@ func (l *PostgresNotificationListener) Listen(channel string) error {
 	return nil
 }
 
+// reconnect replaces the current connection with a new one listening on
+// `channel`.  On failure, the listener is left without a connection.
+func (l *PostgresNotificationListener) reconnect(channel string) error {
+	if l.listener != nil {
+		l.listener.Close()
+		l.listener = nil
+	}
+
+	listener := l.newListener()
+	err := listener.Listen(channel)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+	l.listener = listener
+	return nil
+}
+
+// Connected reports whether the listener's connection is believed to be
+// healthy, i.e. it's listening and its most recent ping succeeded.
+func (l *PostgresNotificationListener) Connected() bool {
+	return l.connected.Load()
+}
+
 func (l *PostgresNotificationListener) Close() error {
-	var err error
 	l.closeOnce.Do(func() {
 		close(l.chStop)
-		err = l.listener.Close()
 		l.wgDone.Wait()
 	})
-	return err
+	return l.closeErr
 }
 
 func (l *PostgresNotificationListener) handleMetaEvent(ev pq.ListenerEventType, err error) {
 	if err != nil {
 		slog.Error("error in postgres listener", "error", err)
 	}
+	switch ev {
+	case pq.ListenerEventDisconnected, pq.ListenerEventConnectionAttemptFailed:
+		l.connected.Store(false)
+	}
 }
 
 func (l *PostgresNotificationListener) RetrieveAll() []string {
@@ -113,31 +272,127 @@ func (l *PostgresNotificationListener) Notify() <-chan struct{} {
 	return l.mbNotifs.Notify()
 }
 
+// Channel returns a channel that receives notification payloads one at a time,
+// and that closes when the listener is closed.  It drains the same mailbox as
+// RetrieveAll, so a given listener should be consumed one way or the other.
+//
+// Notifications are buffered in the listener's mailbox (capacity 1000) while
+// the consumer is busy.  If the consumer falls further behind than that, the
+// oldest buffered notifications are dropped.
+func (l *PostgresNotificationListener) Channel() <-chan string {
+	l.channelOnce.Do(func() {
+		l.chNotifs = make(chan string)
+
+		l.wgDone.Add(1)
+		go func() {
+			defer l.wgDone.Done()
+			defer close(l.chNotifs)
+
+			for {
+				for {
+					notif, exists := l.mbNotifs.Retrieve()
+					if !exists {
+						break
+					}
+					select {
+					case l.chNotifs <- notif:
+					case <-l.chStop:
+						return
+					}
+				}
+
+				select {
+				case <-l.chStop:
+					return
+				case <-l.mbNotifs.Notify():
+				}
+			}
+		}()
+	})
+	return l.chNotifs
+}
+
+// postgresSelector is the subset of *sqlx.DB used by PostgresQueue, so that
+// tests can substitute a fake.
+type postgresSelector interface {
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
 type PostgresQueue[T any] struct {
 	postgresURI         string
-	db                  *sqlx.DB
+	db                  postgresSelector
 	listener            *PostgresNotificationListener
 	notificationChannel string
 	notificationQuery   string
 	catchupQuery        string
+	lastProcessed       func() any
+	acks                *ackTracker[T]
+	clock               Clock
+
+	mbQueue *Mailbox[T]
+	// mbRedeliver holds nacked and expired items in acknowledgement mode.  It's
+	// unbounded so that re-delivery never drops items, even when mbQueue is full.
+	mbRedeliver *Mailbox[T]
+	chStop      chan struct{}
+	wgDone      sync.WaitGroup
+	closeOnce   sync.Once
+}
+
+type PostgresQueueOption func(q *postgresQueueOptions)
+
+type postgresQueueOptions struct {
+	lastProcessed     func() any
+	visibilityTimeout time.Duration
+}
 
-	mbQueue   *Mailbox[T]
-	chStop    chan struct{}
-	wgDone    sync.WaitGroup
-	closeOnce sync.Once
+// WithCheckpoint makes the periodic catchup query incremental: `lastProcessed`
+// is called before each catchup, and its result (e.g. the id or timestamp of
+// the last row the caller processed) is passed to the catchup query as $1, so
+// that it can fetch only newer rows, e.g.
+//
+//	SELECT * FROM jobs WHERE id > $1 ORDER BY id
+func WithCheckpoint(lastProcessed func() any) PostgresQueueOption {
+	return func(opts *postgresQueueOptions) { opts.lastProcessed = lastProcessed }
 }
 
-func NewPostgresQueue[T any](postgresURI string, db *sqlx.DB, notificationChannel string, notificationQuery, catchupQuery string) *PostgresQueue[T] {
-	return &PostgresQueue[T]{
+// WithVisibilityTimeout enables acknowledgement mode, in which items are
+// retrieved with RetrieveAllWithAck and must be acknowledged.  Items that are
+// nacked are re-delivered immediately, and items that are neither acked nor
+// nacked within `timeout` are re-delivered after it expires.  This gives
+// at-least-once processing, but only within this process: in-flight items are
+// tracked in memory and are lost if the process exits.  Re-delivered items are
+// never dropped (unlike new items, which are dropped oldest-first if more than
+// 1000 are waiting), and are retrieved before new items.
+func WithVisibilityTimeout(timeout time.Duration) PostgresQueueOption {
+	return func(opts *postgresQueueOptions) { opts.visibilityTimeout = timeout }
+}
+
+func NewPostgresQueue[T any](postgresURI string, db *sqlx.DB, notificationChannel string, notificationQuery, catchupQuery string, opts ...PostgresQueueOption) *PostgresQueue[T] {
+	var o postgresQueueOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	q := &PostgresQueue[T]{
 		postgresURI:         postgresURI,
 		db:                  db,
 		listener:            NewPostgresNotificationListener(postgresURI, 1*time.Second, 10*time.Second),
 		notificationChannel: notificationChannel,
 		notificationQuery:   notificationQuery,
 		catchupQuery:        catchupQuery,
+		lastProcessed:       o.lastProcessed,
+		clock:               RealClock{},
 		chStop:              make(chan struct{}),
 		mbQueue:             NewMailbox[T](1000),
+		mbRedeliver:         NewMailbox[T](0),
 	}
+	if o.visibilityTimeout > 0 {
+		q.acks = newAckTracker(q.clock, o.visibilityTimeout, func(item T) {
+			q.mbRedeliver.Deliver(item)
+			// Consumers wait on mbQueue's notification channel
+			q.mbQueue.notify()
+		})
+	}
+	return q
 }
 
 func (q *PostgresQueue[T]) Start() error {
@@ -146,12 +401,26 @@ func (q *PostgresQueue[T]) Start() error {
 		return err
 	}
 
-	fetchTicker := time.NewTicker(15 * time.Second)
+	fetchTicker := q.clock.NewTicker(15 * time.Second)
+
+	// In acknowledgement mode, periodically re-deliver items whose visibility
+	// timeout has expired
+	var expireTicker Ticker
+	var chExpire <-chan time.Time
+	if q.acks != nil {
+		expireTicker = q.clock.NewTicker(max(q.acks.timeout/2, 10*time.Millisecond))
+		chExpire = expireTicker.C()
+	}
 
 	q.wgDone.Add(1)
 	go func() {
 		defer q.wgDone.Done()
 		defer fetchTicker.Stop()
+		defer func() {
+			if expireTicker != nil {
+				expireTicker.Stop()
+			}
+		}()
 
 	Outer:
 		for {
@@ -159,17 +428,16 @@ func (q *PostgresQueue[T]) Start() error {
 			case <-q.chStop:
 				return
 
-			case <-fetchTicker.C:
+			case <-fetchTicker.C():
 				// Occasionally run the catchup query in case we missed notifications
-				var rows []T
-				err := q.db.SelectContext(context.TODO(), &rows, q.catchupQuery)
+				err := q.catchup(context.TODO())
 				if err != nil {
 					slog.Error("failed to catchup", "err", err)
 					continue Outer
 				}
-				for _, row := range rows {
-					q.mbQueue.Deliver(row)
-				}
+
+			case <-chExpire:
+				q.acks.expire()
 
 			case <-q.listener.Notify():
 				// When we get notifications, run the notification query
@@ -191,12 +459,48 @@ func (q *PostgresQueue[T]) Start() error {
 	return nil
 }
 
+// catchup runs the catchup query (with the checkpoint, if configured) and
+// delivers the resulting rows.
+func (q *PostgresQueue[T]) catchup(ctx context.Context) error {
+	var args []any
+	if q.lastProcessed != nil {
+		args = append(args, q.lastProcessed())
+	}
+
+	var rows []T
+	err := q.db.SelectContext(ctx, &rows, q.catchupQuery, args...)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		q.mbQueue.Deliver(row)
+	}
+	return nil
+}
+
 func (q *PostgresQueue[T]) Notify() <-chan struct{} {
 	return q.mbQueue.Notify()
 }
 
+// RetrieveAll fetches all queued items, starting with any re-delivered ones.
 func (q *PostgresQueue[T]) RetrieveAll() []T {
-	return q.mbQueue.RetrieveAll()
+	redelivered := q.mbRedeliver.RetrieveAll()
+	return append(redelivered, q.mbQueue.RetrieveAll()...)
+}
+
+// RetrieveAllWithAck is like RetrieveAll, but for queues created with
+// WithVisibilityTimeout: each item must be acknowledged with Ack or Nack.  It
+// panics if acknowledgement mode isn't enabled.
+func (q *PostgresQueue[T]) RetrieveAllWithAck() []QueueDelivery[T] {
+	if q.acks == nil {
+		panic("PostgresQueue.RetrieveAllWithAck requires WithVisibilityTimeout")
+	}
+	items := q.RetrieveAll()
+	deliveries := make([]QueueDelivery[T], len(items))
+	for i, item := range items {
+		deliveries[i] = q.acks.track(item)
+	}
+	return deliveries
 }
 
 func (q *PostgresQueue[T]) Close() error {
@@ -208,3 +512,167 @@ func (q *PostgresQueue[T]) Close() error {
 	})
 	return err
 }
+
+// QueueDelivery is an item retrieved from a PostgresQueue in acknowledgement
+// mode.  See WithVisibilityTimeout.
+type QueueDelivery[T any] struct {
+	Item    T
+	id      uint64
+	tracker *ackTracker[T]
+}
+
+// Ack marks the item as processed.  It returns false if the item's visibility
+// timeout had already expired, in which case it has been (or will be)
+// re-delivered.
+func (d QueueDelivery[T]) Ack() bool {
+	return d.tracker.ack(d.id)
+}
+
+// Nack marks the item as failed, re-delivering it immediately.  It returns
+// false if the item's visibility timeout had already expired.
+func (d QueueDelivery[T]) Nack() bool {
+	return d.tracker.nack(d.id)
+}
+
+// ackTracker tracks in-flight items in memory, re-delivering them if they're
+// nacked or if their visibility timeout expires before they're acked.
+type ackTracker[T any] struct {
+	clock     Clock
+	timeout   time.Duration
+	redeliver func(item T)
+
+	mu       sync.Mutex
+	nextID   uint64
+	inflight map[uint64]inflightItem[T]
+}
+
+type inflightItem[T any] struct {
+	item     T
+	deadline time.Time
+}
+
+func newAckTracker[T any](clock Clock, timeout time.Duration, redeliver func(item T)) *ackTracker[T] {
+	return &ackTracker[T]{
+		clock:     clock,
+		timeout:   timeout,
+		redeliver: redeliver,
+		inflight:  make(map[uint64]inflightItem[T]),
+	}
+}
+
+func (t *ackTracker[T]) track(item T) QueueDelivery[T] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.nextID++
+	t.inflight[t.nextID] = inflightItem[T]{item: item, deadline: t.clock.Now().Add(t.timeout)}
+	return QueueDelivery[T]{Item: item, id: t.nextID, tracker: t}
+}
+
+func (t *ackTracker[T]) ack(id uint64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, exists := t.inflight[id]
+	delete(t.inflight, id)
+	return exists
+}
+
+func (t *ackTracker[T]) nack(id uint64) bool {
+	t.mu.Lock()
+	inflight, exists := t.inflight[id]
+	delete(t.inflight, id)
+	t.mu.Unlock()
+
+	if exists {
+		t.redeliver(inflight.item)
+	}
+	return exists
+}
+
+// expire re-delivers every in-flight item whose deadline has passed.
+func (t *ackTracker[T]) expire() {
+	t.mu.Lock()
+	now := t.clock.Now()
+	var expiredIDs []uint64
+	for id, inflight := range t.inflight {
+		if !inflight.deadline.After(now) {
+			expiredIDs = append(expiredIDs, id)
+		}
+	}
+	// Re-deliver in the order the items were originally handed out
+	slices.Sort(expiredIDs)
+	expired := make([]T, len(expiredIDs))
+	for i, id := range expiredIDs {
+		expired[i] = t.inflight[id].item
+		delete(t.inflight, id)
+	}
+	t.mu.Unlock()
+
+	for _, item := range expired {
+		t.redeliver(item)
+	}
+}
+
+// PoolStats summarizes a database connection pool's saturation.
+type PoolStats struct {
+	MaxOpen      int           // Maximum number of open connections (0 means unlimited)
+	Open         int           // Connections currently open, in use or idle
+	InUse        int           // Connections currently in use
+	Idle         int           // Connections currently idle
+	WaitCount    int64         // Total number of times a caller waited for a connection
+	WaitDuration time.Duration // Total time callers have spent waiting for connections
+}
+
+// Saturation returns the fraction of the maximum pool size that's in use, or 0
+// if the pool size is unlimited.
+func (s PoolStats) Saturation() float64 {
+	if s.MaxOpen <= 0 {
+		return 0
+	}
+	return float64(s.InUse) / float64(s.MaxOpen)
+}
+
+func (s PoolStats) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("max_open", s.MaxOpen),
+		slog.Int("open", s.Open),
+		slog.Int("in_use", s.InUse),
+		slog.Int("idle", s.Idle),
+		slog.Int64("wait_count", s.WaitCount),
+		slog.Duration("wait_duration", s.WaitDuration),
+		slog.Float64("saturation", s.Saturation()),
+	)
+}
+
+func PostgresPoolStats(db *sqlx.DB) PoolStats {
+	return poolStatsFromDBStats(db.Stats())
+}
+
+func poolStatsFromDBStats(s sql.DBStats) PoolStats {
+	return PoolStats{
+		MaxOpen:      s.MaxOpenConnections,
+		Open:         s.OpenConnections,
+		InUse:        s.InUse,
+		Idle:         s.Idle,
+		WaitCount:    s.WaitCount,
+		WaitDuration: s.WaitDuration,
+	}
+}
+
+// LogPostgresPoolStats logs the pool stats of `db` every `interval` using the
+// logger from `ctx` (see LoggerFromContext), until `ctx` is done.
+func LogPostgresPoolStats(ctx context.Context, db *sqlx.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := LoggerFromContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			logger.InfoContext(ctx, "postgres pool stats", "pool", PostgresPoolStats(db))
+		}
+	}
+}
@@ -0,0 +1,418 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePQListener struct {
+	mu       sync.Mutex
+	pingErr  error
+	pings    int
+	closed   bool
+	chNotifs chan *pq.Notification
+}
+
+var _ pqListener = (*fakePQListener)(nil)
+
+func newFakePQListener() *fakePQListener {
+	return &fakePQListener{chNotifs: make(chan *pq.Notification, 10)}
+}
+
+func (f *fakePQListener) Listen(channel string) error { return nil }
+
+func (f *fakePQListener) Ping() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pings++
+	return f.pingErr
+}
+
+func (f *fakePQListener) NotificationChannel() <-chan *pq.Notification { return f.chNotifs }
+
+func (f *fakePQListener) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.closed {
+		f.closed = true
+		close(f.chNotifs)
+	}
+	return nil
+}
+
+func (f *fakePQListener) setPingErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pingErr = err
+}
+
+func (f *fakePQListener) stats() (pings int, closed bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pings, f.closed
+}
+
+// newTestNotificationListener returns a listener whose connections are fakes
+// (in the order they're created) and whose clock is `clock`.
+func newTestNotificationListener(clock Clock, opts ...PostgresListenerOption) (*PostgresNotificationListener, func() []*fakePQListener) {
+	l := NewPostgresNotificationListener("", time.Second, 4*time.Second, opts...)
+	l.clock = clock
+
+	var mu sync.Mutex
+	var fakes []*fakePQListener
+	l.newListener = func() pqListener {
+		mu.Lock()
+		defer mu.Unlock()
+		f := newFakePQListener()
+		fakes = append(fakes, f)
+		return f
+	}
+	return l, func() []*fakePQListener {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*fakePQListener(nil), fakes...)
+	}
+}
+
+func TestPostgresNotificationListener_Reconnect(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	l, fakes := newTestNotificationListener(clock, WithPingInterval(10*time.Second), WithMaxPingFailures(2))
+
+	require.NoError(t, l.Listen("events"))
+	require.True(t, l.Connected())
+	first := fakes()[0]
+
+	waitForPings := func(f *fakePQListener, n int) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			pings, _ := f.stats()
+			return pings == n && clock.Waiters() == 2
+		}, time.Second, time.Millisecond)
+	}
+	requireNoPing := func(f *fakePQListener, n int) {
+		t.Helper()
+		time.Sleep(20 * time.Millisecond)
+		pings, _ := f.stats()
+		require.Equal(t, n, pings)
+	}
+
+	// The first ping fails, so the listener retries after the minimum backoff
+	first.setPingErr(errors.New("connection reset"))
+	clock.Advance(10 * time.Second)
+	waitForPings(first, 1)
+	require.False(t, l.Connected())
+
+	clock.Advance(999 * time.Millisecond)
+	requireNoPing(first, 1)
+	clock.Advance(time.Millisecond)
+	waitForPings(first, 2)
+
+	// After two failures, the next retry (after a doubled backoff) reconnects
+	clock.Advance(1999 * time.Millisecond)
+	requireNoPing(first, 2)
+	clock.Advance(time.Millisecond)
+
+	require.Eventually(t, func() bool { return len(fakes()) == 2 && l.Connected() }, time.Second, time.Millisecond)
+	_, closed := first.stats()
+	require.True(t, closed)
+	second := fakes()[1]
+	pings, _ := second.stats()
+	require.Equal(t, 1, pings)
+
+	// Notifications flow from the new connection
+	second.chNotifs <- &pq.Notification{Extra: "hello"}
+	second.chNotifs <- nil
+	require.Eventually(t, func() bool { return len(l.mbNotifs.RetrieveAll()) == 1 }, time.Second, time.Millisecond)
+
+	// A healthy ping reset the backoff, so the next failure retries after the
+	// minimum delay again
+	second.setPingErr(errors.New("timeout"))
+	clock.Advance(10 * time.Second)
+	waitForPings(second, 2)
+	clock.Advance(time.Second)
+	waitForPings(second, 3)
+
+	require.NoError(t, l.Close())
+	require.False(t, l.Connected())
+	_, closed = second.stats()
+	require.True(t, closed)
+}
+
+func TestPostgresNotificationListener_Close(t *testing.T) {
+	l, _ := newTestNotificationListener(NewFakeClock(time.Now()))
+	require.NoError(t, l.Close())
+
+	l, fakes := newTestNotificationListener(NewFakeClock(time.Now()))
+	require.NoError(t, l.Listen("events"))
+	require.NoError(t, l.Close())
+	require.NoError(t, l.Close())
+	require.False(t, l.Connected())
+
+	_, closed := fakes()[0].stats()
+	require.True(t, closed)
+}
+
+func TestPostgresNotificationListener_Channel(t *testing.T) {
+	l, fakes := newTestNotificationListener(NewFakeClock(time.Now()))
+	require.NoError(t, l.Listen("events"))
+	conn := fakes()[0]
+
+	ch := l.Channel()
+	require.Equal(t, ch, l.Channel())
+
+	for _, payload := range []string{"a", "b", "c"} {
+		conn.chNotifs <- &pq.Notification{Extra: payload}
+	}
+	for _, expected := range []string{"a", "b", "c"} {
+		select {
+		case got := <-ch:
+			require.Equal(t, expected, got)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %q", expected)
+		}
+	}
+
+	require.NoError(t, l.Close())
+	_, open := <-ch
+	require.False(t, open)
+}
+
+type testJob struct {
+	ID int
+}
+
+// fakeJobTable answers queries with the rows whose ID is greater than the
+// first argument (or all rows, if there are no arguments).
+type fakeJobTable struct {
+	rows    []testJob
+	queries []string
+	args    [][]any
+}
+
+func (f *fakeJobTable) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, args)
+
+	out := dest.(*[]testJob)
+	for _, row := range f.rows {
+		if len(args) == 0 || row.ID > args[0].(int) {
+			*out = append(*out, row)
+		}
+	}
+	return nil
+}
+
+func TestPostgresQueue_Checkpoint(t *testing.T) {
+	const catchupQuery = "SELECT id FROM jobs WHERE id > $1 ORDER BY id"
+	table := &fakeJobTable{rows: []testJob{{1}, {2}, {3}, {4}}}
+
+	lastProcessed := 2
+	q := NewPostgresQueue[testJob]("", nil, "jobs", "", catchupQuery, WithCheckpoint(func() any { return lastProcessed }))
+	q.db = table
+
+	require.NoError(t, q.catchup(context.Background()))
+	require.Equal(t, []string{catchupQuery}, table.queries)
+	require.Equal(t, [][]any{{2}}, table.args)
+	require.Equal(t, []testJob{{3}, {4}}, q.RetrieveAll())
+
+	lastProcessed = 4
+	require.NoError(t, q.catchup(context.Background()))
+	require.Equal(t, []any{4}, table.args[1])
+	require.Empty(t, q.RetrieveAll())
+
+	t.Run("without a checkpoint", func(t *testing.T) {
+		table := &fakeJobTable{rows: []testJob{{1}, {2}}}
+		q := NewPostgresQueue[testJob]("", nil, "jobs", "", "SELECT id FROM jobs")
+		q.db = table
+
+		require.NoError(t, q.catchup(context.Background()))
+		require.Equal(t, [][]any{nil}, table.args)
+		require.Equal(t, []testJob{{1}, {2}}, q.RetrieveAll())
+	})
+}
+
+func TestPostgresQueue_Ack(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	q := NewPostgresQueue[testJob]("", nil, "jobs", "", "", WithVisibilityTimeout(time.Minute))
+	q.acks.clock = clock
+
+	for i := 1; i <= 4; i++ {
+		q.mbQueue.Deliver(testJob{i})
+	}
+	deliveries := q.RetrieveAllWithAck()
+	require.Len(t, deliveries, 4)
+	require.Equal(t, testJob{1}, deliveries[0].Item)
+
+	require.True(t, deliveries[0].Ack())
+	require.False(t, deliveries[0].Ack())
+
+	// Nacked items are re-delivered immediately
+	require.True(t, deliveries[1].Nack())
+	require.Equal(t, []testJob{{2}}, q.RetrieveAll())
+
+	// Un-acked items are re-delivered once the visibility timeout expires
+	clock.Advance(59 * time.Second)
+	q.acks.expire()
+	require.Empty(t, q.RetrieveAll())
+
+	clock.Advance(time.Second)
+	q.acks.expire()
+	redelivered := q.RetrieveAllWithAck()
+	require.Equal(t, []testJob{{3}, {4}}, []testJob{redelivered[0].Item, redelivered[1].Item})
+
+	// Acking the original delivery after it timed out is too late
+	require.False(t, deliveries[2].Ack())
+	require.True(t, redelivered[0].Ack())
+	require.True(t, redelivered[1].Ack())
+
+	clock.Advance(time.Hour)
+	q.acks.expire()
+	require.Empty(t, q.RetrieveAll())
+
+	t.Run("requires acknowledgement mode", func(t *testing.T) {
+		q := NewPostgresQueue[testJob]("", nil, "jobs", "", "")
+		require.Panics(t, func() { q.RetrieveAllWithAck() })
+	})
+}
+
+func TestPostgresQueue_RedeliveryThroughStart(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	q := NewPostgresQueue[testJob]("", nil, "jobs", "", "", WithVisibilityTimeout(time.Minute))
+	q.clock = clock
+	q.acks.clock = clock
+	q.db = &fakeJobTable{}
+	q.listener, _ = newTestNotificationListener(clock)
+
+	require.NoError(t, q.Start())
+	defer q.Close()
+
+	waitNotify := func() {
+		t.Helper()
+		select {
+		case <-q.Notify():
+		case <-time.After(time.Second):
+			t.Fatal("no notification")
+		}
+	}
+
+	q.mbQueue.Deliver(testJob{1})
+	q.mbQueue.Deliver(testJob{2})
+	waitNotify()
+	deliveries := q.RetrieveAllWithAck()
+	require.Len(t, deliveries, 2)
+	require.True(t, deliveries[0].Ack())
+
+	// Fill the mailbox to capacity: re-delivered items must still survive
+	for i := 100; i < 1100; i++ {
+		q.mbQueue.Deliver(testJob{i})
+	}
+	waitNotify()
+
+	// The queue's expire ticker re-delivers the un-acked item, ahead of the
+	// new ones
+	clock.Advance(time.Minute)
+	waitNotify()
+
+	redelivered := q.RetrieveAllWithAck()
+	require.Len(t, redelivered, 1001)
+	require.Equal(t, testJob{2}, redelivered[0].Item)
+	require.Equal(t, testJob{100}, redelivered[1].Item)
+	require.False(t, deliveries[1].Ack())
+	require.True(t, redelivered[0].Ack())
+}
+
+func TestQuotePostgresIdentifier(t *testing.T) {
+	for name, expected := range map[string]string{
+		"jobs":                   `"jobs"`,
+		"job_events_2024":        `"job_events_2024"`,
+		"Mixed Case":             `"Mixed Case"`,
+		"naïve":                  `"naïve"`,
+		`jobs"; DROP TABLE x;--`: `"jobs""; DROP TABLE x;--"`,
+		`""`:                     `""""""`,
+	} {
+		quoted, err := QuotePostgresIdentifier(name)
+		require.NoError(t, err, name)
+		require.Equal(t, expected, quoted, name)
+	}
+
+	for _, name := range []string{
+		"",
+		"jobs\x00; DROP TABLE x",
+		"bad\xffutf8",
+		strings.Repeat("x", 64),
+	} {
+		_, err := QuotePostgresIdentifier(name)
+		require.Error(t, err, name)
+	}
+
+	_, err := QuotePostgresIdentifier(strings.Repeat("x", 63))
+	require.NoError(t, err)
+
+	t.Run("Listen rejects invalid channel names", func(t *testing.T) {
+		l, fakes := newTestNotificationListener(NewFakeClock(time.Now()))
+		require.Error(t, l.Listen("jobs\x00"))
+		require.Empty(t, fakes())
+	})
+}
+
+func TestPostgresPoolStats(t *testing.T) {
+	stats := poolStatsFromDBStats(sql.DBStats{
+		MaxOpenConnections: 20,
+		OpenConnections:    8,
+		InUse:              5,
+		Idle:               3,
+		WaitCount:          42,
+		WaitDuration:       3 * time.Second,
+		MaxIdleClosed:      1,
+	})
+	require.Equal(t, PoolStats{
+		MaxOpen:      20,
+		Open:         8,
+		InUse:        5,
+		Idle:         3,
+		WaitCount:    42,
+		WaitDuration: 3 * time.Second,
+	}, stats)
+	require.Equal(t, 0.25, stats.Saturation())
+	require.Equal(t, 0.0, PoolStats{InUse: 5}.Saturation())
+
+	t.Run("from a live pool", func(t *testing.T) {
+		db, err := sqlx.Open("postgres", "postgres://localhost/unused")
+		require.NoError(t, err)
+		defer db.Close()
+		db.SetMaxOpenConns(7)
+
+		require.Equal(t, PoolStats{MaxOpen: 7}, PostgresPoolStats(db))
+	})
+
+	t.Run("logging", func(t *testing.T) {
+		var buf bytes.Buffer
+		ctx, cancel := context.WithCancel(LoggerIntoContext(context.Background(), slog.New(slog.NewJSONHandler(&buf, nil))))
+
+		db, err := sqlx.Open("postgres", "postgres://localhost/unused")
+		require.NoError(t, err)
+		defer db.Close()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			LogPostgresPoolStats(ctx, db, time.Millisecond)
+		}()
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		<-done
+
+		require.Contains(t, buf.String(), `"pool":{"max_open":0,"open":0,"in_use":0,"idle":0,"wait_count":0`)
+	})
+}
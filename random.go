@@ -1,10 +1,14 @@
 package utils
 
 import (
+	crand "crypto/rand"
+	"math/big"
 	"math/rand"
 	"strconv"
 
 	"github.com/google/uuid"
+
+	"github.com/brynbellomy/go-utils/errors"
 )
 
 func RandomNumberString() string {
@@ -36,3 +40,46 @@ func MustUUIDv7() string {
 	}
 	return vid.String()
 }
+
+// DefaultShortCodeAlphabet is the alphabet used by ShortCode unless overridden.
+// It's uppercase and excludes the visually-ambiguous characters 0/O and 1/I/L.
+const DefaultShortCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+type shortCodeOptions struct {
+	alphabet string
+}
+
+type ShortCodeOption func(*shortCodeOptions)
+
+// WithAlphabet overrides the characters ShortCode draws from.
+func WithAlphabet(alphabet string) ShortCodeOption {
+	return func(opts *shortCodeOptions) { opts.alphabet = alphabet }
+}
+
+// ShortCode generates a cryptographically random code of `length` characters,
+// suitable for invite codes and the like.  `length` must be positive.
+func ShortCode(length int, opts ...ShortCodeOption) (string, error) {
+	if length <= 0 {
+		return "", errors.Errorf("invalid short code length %d", length)
+	}
+	options := shortCodeOptions{alphabet: DefaultShortCodeAlphabet}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	alphabet := []rune(options.alphabet)
+	if len(alphabet) == 0 {
+		return "", errors.New("empty alphabet")
+	}
+	limit := big.NewInt(int64(len(alphabet)))
+
+	code := make([]rune, length)
+	for i := range code {
+		n, err := crand.Int(crand.Reader, limit)
+		if err != nil {
+			return "", err
+		}
+		code[i] = alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
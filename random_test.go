@@ -0,0 +1,43 @@
+package utils_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestShortCode(t *testing.T) {
+	t.Run("default alphabet", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			code, err := utils.ShortCode(8)
+			require.NoError(t, err)
+			require.Len(t, code, 8)
+			require.Equal(t, strings.ToUpper(code), code)
+			for _, c := range code {
+				require.True(t, strings.ContainsRune(utils.DefaultShortCodeAlphabet, c))
+			}
+			require.False(t, strings.ContainsAny(code, "0O1IL"))
+		}
+	})
+
+	t.Run("custom alphabet", func(t *testing.T) {
+		code, err := utils.ShortCode(32, utils.WithAlphabet("ab"))
+		require.NoError(t, err)
+		require.Len(t, code, 32)
+		require.Empty(t, strings.Trim(code, "ab"))
+	})
+
+	t.Run("empty alphabet", func(t *testing.T) {
+		_, err := utils.ShortCode(4, utils.WithAlphabet(""))
+		require.Error(t, err)
+	})
+	t.Run("non-positive length", func(t *testing.T) {
+		for _, length := range []int{0, -1} {
+			_, err := utils.ShortCode(length)
+			require.Error(t, err)
+		}
+	})
+}
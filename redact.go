@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+)
+
+const redactedPlaceholder = "***"
+
+// DefaultSensitiveKeys are the struct field names and map keys that Redact
+// treats as sensitive by default.  Matching ignores case, underscores, and
+// hyphens, so "APIKey", "api_key", and "Api-Key" all match "apikey".
+var DefaultSensitiveKeys = []string{"password", "passwd", "secret", "token", "apikey", "authorization", "cookie"}
+
+type RedactOption func(opts *redactOptions)
+
+type redactOptions struct {
+	sensitiveKeys map[string]struct{}
+}
+
+// WithSensitiveKeys replaces DefaultSensitiveKeys with `keys`.
+func WithSensitiveKeys(keys ...string) RedactOption {
+	return func(opts *redactOptions) {
+		opts.sensitiveKeys = make(map[string]struct{}, len(keys))
+		for _, k := range keys {
+			opts.sensitiveKeys[normalizeSensitiveKey(k)] = struct{}{}
+		}
+	}
+}
+
+// Redact returns a deep copy of `v`, suitable for logging, in which struct
+// fields tagged `redact:"true"` and struct fields or string map keys matching
+// the sensitive key list are replaced with "***".  Nested structs, pointers,
+// maps, slices, and arrays are walked recursively; pointers that alias each
+// other in `v` (including cycles) alias the corresponding copies, as with
+// DeepCopy.  `v` itself is never modified.
+//
+// Since the copy has the same type as `v`, only string (and interface) values
+// can hold the placeholder; redacted values of other types are zeroed instead.
+// Unexported struct fields are copied shallowly and never redacted.
+func Redact(v any, opts ...RedactOption) any {
+	o := redactOptions{}
+	WithSensitiveKeys(DefaultSensitiveKeys...)(&o)
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	val := reflect.ValueOf(v)
+	if !val.IsValid() {
+		return nil
+	}
+	return redactValue(val, &o, make(map[visitedPointer]reflect.Value)).Interface()
+}
+
+func redactValue(val reflect.Value, opts *redactOptions, visited map[visitedPointer]reflect.Value) reflect.Value {
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			return val
+		}
+		key := visitedPointer{val.Pointer(), val.Type()}
+		if redacted, exists := visited[key]; exists {
+			return redacted
+		}
+		out := reflect.New(val.Type().Elem())
+		visited[key] = out
+		out.Elem().Set(redactValue(val.Elem(), opts, visited))
+		return out
+
+	case reflect.Interface:
+		if val.IsNil() {
+			return val
+		}
+		out := reflect.New(val.Type()).Elem()
+		out.Set(redactValue(val.Elem(), opts, visited))
+		return out
+
+	case reflect.Struct:
+		typ := val.Type()
+		out := reflect.New(typ).Elem()
+		out.Set(val)
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if field.Tag.Get("redact") == "true" || opts.isSensitive(field.Name) {
+				out.Field(i).Set(redactedValue(field.Type))
+			} else {
+				out.Field(i).Set(redactValue(val.Field(i), opts, visited))
+			}
+		}
+		return out
+
+	case reflect.Map:
+		if val.IsNil() {
+			return val
+		}
+		out := reflect.MakeMapWithSize(val.Type(), val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			k := iter.Key()
+			if k.Kind() == reflect.String && opts.isSensitive(k.String()) {
+				out.SetMapIndex(k, redactedValue(val.Type().Elem()))
+			} else {
+				out.SetMapIndex(k, redactValue(iter.Value(), opts, visited))
+			}
+		}
+		return out
+
+	case reflect.Slice:
+		if val.IsNil() {
+			return val
+		}
+		out := reflect.MakeSlice(val.Type(), val.Len(), val.Len())
+		for i := 0; i < val.Len(); i++ {
+			out.Index(i).Set(redactValue(val.Index(i), opts, visited))
+		}
+		return out
+
+	case reflect.Array:
+		out := reflect.New(val.Type()).Elem()
+		for i := 0; i < val.Len(); i++ {
+			out.Index(i).Set(redactValue(val.Index(i), opts, visited))
+		}
+		return out
+
+	default:
+		return val
+	}
+}
+
+func redactedValue(typ reflect.Type) reflect.Value {
+	switch typ.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(redactedPlaceholder).Convert(typ)
+	case reflect.Interface:
+		placeholder := reflect.ValueOf(redactedPlaceholder)
+		if placeholder.Type().Implements(typ) {
+			out := reflect.New(typ).Elem()
+			out.Set(placeholder)
+			return out
+		}
+	}
+	return reflect.Zero(typ)
+}
+
+func (opts *redactOptions) isSensitive(key string) bool {
+	_, exists := opts.sensitiveKeys[normalizeSensitiveKey(key)]
+	return exists
+}
+
+func normalizeSensitiveKey(key string) string {
+	key = strings.ToLower(key)
+	return strings.NewReplacer("_", "", "-", "").Replace(key)
+}
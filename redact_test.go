@@ -0,0 +1,88 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+type redactCredentials struct {
+	Username string
+	Password string
+	PIN      int `redact:"true"`
+}
+
+type redactRequest struct {
+	ID      int
+	Creds   *redactCredentials
+	Headers map[string]string
+	Extra   map[string]any
+	Items   []redactCredentials
+	Note    string `redact:"true"`
+	Public  string `redact:"false"`
+}
+
+func TestRedact(t *testing.T) {
+	original := redactRequest{
+		ID:      1,
+		Creds:   &redactCredentials{Username: "jane", Password: "hunter2", PIN: 1234},
+		Headers: map[string]string{"Authorization": "Bearer abc", "Accept": "text/plain"},
+		Extra:   map[string]any{"api_key": 42, "nested": map[string]any{"Secret": "s", "ok": "fine"}},
+		Items:   []redactCredentials{{Username: "bob", Password: "pw"}},
+		Note:    "private",
+		Public:  "visible",
+	}
+
+	redacted := utils.Redact(original).(redactRequest)
+
+	require.Equal(t, redactRequest{
+		ID:      1,
+		Creds:   &redactCredentials{Username: "jane", Password: "***", PIN: 0},
+		Headers: map[string]string{"Authorization": "***", "Accept": "text/plain"},
+		Extra:   map[string]any{"api_key": "***", "nested": map[string]any{"Secret": "***", "ok": "fine"}},
+		Items:   []redactCredentials{{Username: "bob", Password: "***"}},
+		Note:    "***",
+		Public:  "visible",
+	}, redacted)
+
+	t.Run("the original is unmodified", func(t *testing.T) {
+		require.Equal(t, "hunter2", original.Creds.Password)
+		require.Equal(t, 1234, original.Creds.PIN)
+		require.Equal(t, "Bearer abc", original.Headers["Authorization"])
+		require.Equal(t, 42, original.Extra["api_key"])
+		require.Equal(t, "s", original.Extra["nested"].(map[string]any)["Secret"])
+		require.Equal(t, "pw", original.Items[0].Password)
+		require.Equal(t, "private", original.Note)
+		require.NotSame(t, original.Creds, redacted.Creds)
+	})
+
+	t.Run("pointers and custom keys", func(t *testing.T) {
+		m := map[string]string{"ssn": "123-45-6789", "password": "kept"}
+		require.Equal(t,
+			map[string]string{"ssn": "***", "password": "kept"},
+			utils.Redact(m, utils.WithSensitiveKeys("SSN")),
+		)
+
+		creds := &redactCredentials{Password: "x"}
+		require.Equal(t, &redactCredentials{Password: "***"}, utils.Redact(creds))
+		require.Nil(t, utils.Redact(nil))
+	})
+	t.Run("cycles", func(t *testing.T) {
+		type node struct {
+			Token    string
+			Parent   *node
+			Children []*node
+		}
+		root := &node{Token: "root-token"}
+		child := &node{Token: "child-token", Parent: root}
+		root.Children = []*node{child}
+
+		redacted := utils.Redact(root).(*node)
+		require.Equal(t, "***", redacted.Token)
+		require.Equal(t, "***", redacted.Children[0].Token)
+		require.Same(t, redacted, redacted.Children[0].Parent)
+		require.Equal(t, "root-token", root.Token)
+	})
+}
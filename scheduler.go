@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Scheduler runs jobs periodically or at a fixed time.  Each run happens in its
+// own goroutine, and panics are recovered and logged.
+type Scheduler struct {
+	clock Clock
+	wg    sync.WaitGroup
+}
+
+// NewScheduler creates a Scheduler driven by `clock` (RealClock if nil).
+func NewScheduler(clock Clock) *Scheduler {
+	if clock == nil {
+		clock = RealClock{}
+	}
+	return &Scheduler{clock: clock}
+}
+
+// ScheduledJob is a job definition returned by Scheduler.Every and
+// Scheduler.At.  Call Do to start it.
+type ScheduledJob struct {
+	scheduler    *Scheduler
+	interval     time.Duration
+	at           time.Time
+	allowOverlap bool
+	running      atomic.Bool
+}
+
+// Every defines a job that runs every `interval`, starting one interval from
+// when Do is called.
+func (s *Scheduler) Every(interval time.Duration) *ScheduledJob {
+	return &ScheduledJob{scheduler: s, interval: interval}
+}
+
+// At defines a job that runs once at time `t` (immediately if `t` has
+// passed).
+func (s *Scheduler) At(t time.Time) *ScheduledJob {
+	return &ScheduledJob{scheduler: s, at: t}
+}
+
+// AllowOverlap lets a periodic job start a new run while the previous one is
+// still going.  By default, ticks that arrive while the job is running are
+// skipped.
+func (j *ScheduledJob) AllowOverlap() *ScheduledJob {
+	j.allowOverlap = true
+	return j
+}
+
+// Do starts scheduling `fn` until `ctx` is done.  `ctx` is also passed to each
+// run so that long-running jobs can stop early.
+func (j *ScheduledJob) Do(ctx context.Context, fn func(ctx context.Context)) {
+	s := j.scheduler
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		if j.interval == 0 {
+			select {
+			case <-ctx.Done():
+			case <-s.clock.After(j.at.Sub(s.clock.Now())):
+				j.run(ctx, fn)
+			}
+			return
+		}
+
+		ticker := s.clock.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				if !j.allowOverlap && j.running.Load() {
+					slog.Debug("skipping scheduled job run, previous run still in progress")
+					continue
+				}
+				j.run(ctx, fn)
+			}
+		}
+	}()
+}
+
+func (j *ScheduledJob) run(ctx context.Context, fn func(ctx context.Context)) {
+	s := j.scheduler
+	j.running.Store(true)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer j.running.Store(false)
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("scheduled job panicked", "panic", r, "stack", string(debug.Stack()))
+			}
+		}()
+		fn(ctx)
+	}()
+}
+
+// Wait blocks until every job has stopped (because its context is done) and
+// all in-progress runs have returned.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
@@ -0,0 +1,85 @@
+package utils_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestScheduler(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Every runs on schedule", func(t *testing.T) {
+		clock := utils.NewFakeClock(start)
+		s := utils.NewScheduler(clock)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var runs atomic.Int32
+		s.Every(time.Minute).Do(ctx, func(ctx context.Context) { runs.Add(1) })
+		require.Eventually(t, func() bool { return clock.Waiters() == 1 }, time.Second, time.Millisecond)
+
+		clock.Advance(30 * time.Second)
+		time.Sleep(10 * time.Millisecond)
+		require.Equal(t, int32(0), runs.Load())
+
+		for i := 1; i <= 3; i++ {
+			clock.Advance(time.Minute)
+			require.Eventually(t, func() bool { return runs.Load() == int32(i) }, time.Second, time.Millisecond)
+		}
+
+		cancel()
+		s.Wait()
+	})
+
+	t.Run("long-running jobs don't overlap", func(t *testing.T) {
+		clock := utils.NewFakeClock(start)
+		s := utils.NewScheduler(clock)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var runs, concurrent, maxConcurrent atomic.Int32
+		release := make(chan struct{})
+		s.Every(time.Second).Do(ctx, func(ctx context.Context) {
+			runs.Add(1)
+			if n := concurrent.Add(1); n > maxConcurrent.Load() {
+				maxConcurrent.Store(n)
+			}
+			defer concurrent.Add(-1)
+			<-release
+		})
+		require.Eventually(t, func() bool { return clock.Waiters() == 1 }, time.Second, time.Millisecond)
+
+		clock.Advance(time.Second)
+		require.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+		for i := 0; i < 5; i++ {
+			clock.Advance(time.Second)
+			time.Sleep(5 * time.Millisecond)
+		}
+		require.Equal(t, int32(1), runs.Load())
+		require.Equal(t, int32(1), maxConcurrent.Load())
+
+		close(release)
+		cancel()
+		s.Wait()
+	})
+
+	t.Run("At runs once and recovers panics", func(t *testing.T) {
+		clock := utils.NewFakeClock(start)
+		s := utils.NewScheduler(clock)
+
+		var runs atomic.Int32
+		s.At(start.Add(time.Hour)).Do(context.Background(), func(ctx context.Context) {
+			runs.Add(1)
+			panic("boom")
+		})
+		require.Eventually(t, func() bool { return clock.Waiters() == 1 }, time.Second, time.Millisecond)
+
+		clock.Advance(time.Hour)
+		s.Wait()
+		require.Equal(t, int32(1), runs.Load())
+	})
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+)
+
+// Semaphore is a counting semaphore that bounds the number of concurrent
+// holders to `n`.
+type Semaphore struct {
+	slots chan struct{}
+}
+
+func NewSemaphore(n int) *Semaphore {
+	if n <= 0 {
+		panic("NewSemaphore: n must be positive")
+	}
+	return &Semaphore{slots: make(chan struct{}, n)}
+}
+
+// Acquire blocks until a slot is available or `ctx` is done, in which case it
+// returns ctx.Err().
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryAcquire acquires a slot if one is immediately available, and reports
+// whether it did.
+func (s *Semaphore) TryAcquire() bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release releases a slot acquired with Acquire or TryAcquire.  It panics if no
+// slots are held.
+func (s *Semaphore) Release() {
+	select {
+	case <-s.slots:
+	default:
+		panic("Semaphore: Release called more times than Acquire")
+	}
+}
@@ -0,0 +1,69 @@
+package utils_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestSemaphore(t *testing.T) {
+	t.Run("bounds concurrency", func(t *testing.T) {
+		sem := utils.NewSemaphore(3)
+
+		var current, peak atomic.Int32
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				require.NoError(t, sem.Acquire(context.Background()))
+				defer sem.Release()
+
+				n := current.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				current.Add(-1)
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int32(3), peak.Load())
+	})
+
+	t.Run("TryAcquire", func(t *testing.T) {
+		sem := utils.NewSemaphore(2)
+		require.True(t, sem.TryAcquire())
+		require.True(t, sem.TryAcquire())
+		require.False(t, sem.TryAcquire())
+
+		sem.Release()
+		require.True(t, sem.TryAcquire())
+	})
+
+	t.Run("Acquire returns the context's error when cancelled", func(t *testing.T) {
+		sem := utils.NewSemaphore(1)
+		require.NoError(t, sem.Acquire(context.Background()))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, sem.Acquire(ctx), context.DeadlineExceeded)
+
+		sem.Release()
+		require.NoError(t, sem.Acquire(context.Background()))
+	})
+
+	t.Run("over-release panics", func(t *testing.T) {
+		sem := utils.NewSemaphore(1)
+		require.PanicsWithValue(t, "Semaphore: Release called more times than Acquire", sem.Release)
+	})
+}
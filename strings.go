@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var interpolateCache = NewSyncMap[string, *template.Template]()
+
+// Interpolate executes `tmpl` as a text/template against `data`, e.g.
+// Interpolate("/users/{{.User.ID}}", req).  Compiled templates are cached by
+// their source, so repeated calls with the same template only parse it once.
+// Referencing a field or map key that doesn't exist is an error.
+func Interpolate(tmpl string, data any) (string, error) {
+	t, exists := interpolateCache.Get(tmpl)
+	if !exists {
+		var err error
+		t, err = template.New("").Option("missingkey=error").Parse(tmpl)
+		if err != nil {
+			return "", err
+		}
+		interpolateCache.Set(tmpl, t)
+	}
+
+	var sb strings.Builder
+	err := t.Execute(&sb, data)
+	if err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// slugTransliterations maps common accented Latin characters (and a few
+// ligatures) to ASCII.  Characters that aren't listed here and aren't ASCII
+// alphanumerics are treated as separators.
+var slugTransliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ĉ': "c", 'ċ': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ĝ': "g", 'ğ': "g", 'ġ': "g", 'ģ': "g", 'ĥ': "h", 'ħ': "h",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i", 'ĵ': "j", 'ķ': "k",
+	'ĺ': "l", 'ļ': "l", 'ľ': "l", 'ł': "l", 'ñ': "n", 'ń': "n", 'ņ': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ŕ': "r", 'ř': "r", 'ś': "s", 'ŝ': "s", 'ş': "s", 'š': "s", 'ß': "ss",
+	'ţ': "t", 'ť': "t", 'þ': "th", 'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ŭ': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ŵ': "w", 'ý': "y", 'ÿ': "y", 'ŷ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+type SlugifyOption func(opts *slugifyOptions)
+
+type slugifyOptions struct {
+	maxLength int
+}
+
+// WithMaxSlugLength truncates slugs to at most `n` bytes, without leaving a
+// trailing hyphen.
+func WithMaxSlugLength(n int) SlugifyOption {
+	return func(opts *slugifyOptions) { opts.maxLength = n }
+}
+
+// Slugify converts `s` into a URL-friendly identifier: lowercase ASCII letters
+// and digits, with each run of anything else replaced by a single hyphen, and
+// no leading or trailing hyphens.  Common accented characters are
+// transliterated to ASCII ("Crème Brûlée" becomes "creme-brulee").
+func Slugify(s string, opts ...SlugifyOption) string {
+	var o slugifyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var sb strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(s) {
+		var out string
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			out = string(r)
+		default:
+			out = slugTransliterations[r]
+		}
+		if out == "" {
+			pendingHyphen = true
+			continue
+		}
+		if pendingHyphen && sb.Len() > 0 {
+			sb.WriteByte('-')
+		}
+		pendingHyphen = false
+		sb.WriteString(out)
+	}
+
+	slug := sb.String()
+	if o.maxLength > 0 && len(slug) > o.maxLength {
+		slug = strings.TrimRight(slug[:o.maxLength], "-")
+	}
+	return slug
+}
+
+// UniqueSlug returns `slug` if `exists` reports that it isn't taken, and
+// otherwise the first of `slug-2`, `slug-3`, ... that isn't.
+func UniqueSlug(slug string, exists func(string) bool) string {
+	candidate := slug
+	for i := 2; exists(candidate); i++ {
+		candidate = fmt.Sprintf("%v-%d", slug, i)
+	}
+	return candidate
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate_Cache(t *testing.T) {
+	const tmpl = "cached {{.}}"
+
+	s, err := Interpolate(tmpl, 1)
+	require.NoError(t, err)
+	require.Equal(t, "cached 1", s)
+
+	compiled, exists := interpolateCache.Get(tmpl)
+	require.True(t, exists)
+
+	s, err = Interpolate(tmpl, 2)
+	require.NoError(t, err)
+	require.Equal(t, "cached 2", s)
+
+	again, _ := interpolateCache.Get(tmpl)
+	require.Same(t, compiled, again)
+}
@@ -0,0 +1,66 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestInterpolate(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	type request struct {
+		User  user
+		Query map[string]string
+	}
+	data := request{User: user{ID: 7, Name: "jane"}, Query: map[string]string{"sort": "asc"}}
+
+	s, err := utils.Interpolate("hello, {{.User.Name}}", data)
+	require.NoError(t, err)
+	require.Equal(t, "hello, jane", s)
+
+	s, err = utils.Interpolate("/users/{{.User.ID}}?sort={{.Query.sort}}", data)
+	require.NoError(t, err)
+	require.Equal(t, "/users/7?sort=asc", s)
+
+	_, err = utils.Interpolate("{{.User.Email}}", data)
+	require.Error(t, err)
+
+	_, err = utils.Interpolate("{{.Query.missing}}", data)
+	require.Error(t, err)
+
+	_, err = utils.Interpolate("{{.User.Name", data)
+	require.Error(t, err)
+}
+
+func TestSlugify(t *testing.T) {
+	for input, expected := range map[string]string{
+		"Hello World":                "hello-world",
+		"Crème Brûlée à la Façon":    "creme-brulee-a-la-facon",
+		"Straße & Œuvre":             "strasse-oeuvre",
+		"What's up?! (2024 edition)": "what-s-up-2024-edition",
+		"multiple    spaces\there":   "multiple-spaces-here",
+		"--leading and trailing--":   "leading-and-trailing",
+		"  !!!  ":                    "",
+		"":                           "",
+		"日本語 title":                  "title",
+	} {
+		require.Equal(t, expected, utils.Slugify(input), input)
+	}
+
+	require.Equal(t, "hello", utils.Slugify("Hello World", utils.WithMaxSlugLength(6)))
+	require.Equal(t, "hello-w", utils.Slugify("Hello World", utils.WithMaxSlugLength(7)))
+	require.Equal(t, "hello-world", utils.Slugify("Hello World", utils.WithMaxSlugLength(100)))
+}
+
+func TestUniqueSlug(t *testing.T) {
+	taken := map[string]bool{"post": true, "post-2": true}
+	exists := func(s string) bool { return taken[s] }
+
+	require.Equal(t, "post-3", utils.UniqueSlug("post", exists))
+	require.Equal(t, "other", utils.UniqueSlug("other", exists))
+}
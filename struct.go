@@ -1,8 +1,13 @@
 package utils
 
 import (
+	"encoding"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 func StructFieldNames(myStruct any) []string {
@@ -53,3 +58,170 @@ func StructFieldValueStrings(myStruct any) []string {
 	}
 	return fieldValues
 }
+
+// StructKey returns a stable string derived from the exported fields of `v`,
+// suitable for use as a map key when caching or deduplicating on struct
+// values.  Nested structs, pointers, slices, and maps (ordered by key) are
+// walked recursively, and values held in interfaces are prefixed with their
+// dynamic type, so that `any(1)` and `any(1.0)` produce different keys.
+// Structs implementing encoding.TextMarshaler or json.Marshaler (like
+// time.Time, whose fields are all unexported) are keyed by their marshaled
+// form; other structs are keyed by their exported fields only.  A pointer,
+// map, or slice that refers back to one of its own ancestors (a cycle) is
+// written as a back-reference, "^N", to the ancestor N levels up.
+func StructKey(v any) string {
+	var sb strings.Builder
+	writeStructKey(&sb, reflect.ValueOf(v), make(map[visitedPointer]int))
+	return sb.String()
+}
+
+// writeStructKey writes the key for `val` to `sb`.  `ancestors` maps the
+// pointers, maps, and slices currently being walked to their depth.
+func writeStructKey(sb *strings.Builder, val reflect.Value, ancestors map[visitedPointer]int) {
+	if !val.IsValid() {
+		sb.WriteString("nil")
+		return
+	}
+
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			sb.WriteString("nil")
+			return
+		}
+		if leave, ok := enterStructKeyAncestor(sb, val, ancestors); ok {
+			defer leave()
+			writeStructKey(sb, val.Elem(), ancestors)
+		}
+
+	case reflect.Interface:
+		if val.IsNil() {
+			sb.WriteString("nil")
+			return
+		}
+		sb.WriteString(val.Elem().Type().String())
+		sb.WriteString("(")
+		writeStructKey(sb, val.Elem(), ancestors)
+		sb.WriteString(")")
+
+	case reflect.Struct:
+		if marshaled, ok := marshalStructKey(val); ok {
+			sb.WriteString(strconv.Quote(marshaled))
+			return
+		}
+		typ := val.Type()
+		sb.WriteString("{")
+		first := true
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			if !first {
+				sb.WriteString(",")
+			}
+			first = false
+			sb.WriteString(field.Name)
+			sb.WriteString(":")
+			writeStructKey(sb, val.Field(i), ancestors)
+		}
+		sb.WriteString("}")
+
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			sb.WriteString("nil")
+			return
+		}
+		if val.Kind() == reflect.Slice {
+			leave, ok := enterStructKeyAncestor(sb, val, ancestors)
+			if !ok {
+				return
+			}
+			defer leave()
+		}
+		sb.WriteString("[")
+		for i := 0; i < val.Len(); i++ {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			writeStructKey(sb, val.Index(i), ancestors)
+		}
+		sb.WriteString("]")
+
+	case reflect.Map:
+		if val.IsNil() {
+			sb.WriteString("nil")
+			return
+		}
+		leave, ok := enterStructKeyAncestor(sb, val, ancestors)
+		if !ok {
+			return
+		}
+		defer leave()
+		entries := make([][2]string, 0, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			var k, v strings.Builder
+			writeStructKey(&k, iter.Key(), ancestors)
+			writeStructKey(&v, iter.Value(), ancestors)
+			entries = append(entries, [2]string{k.String(), v.String()})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i][0] < entries[j][0] })
+
+		sb.WriteString("map[")
+		for i, entry := range entries {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString(entry[0])
+			sb.WriteString(":")
+			sb.WriteString(entry[1])
+		}
+		sb.WriteString("]")
+
+	case reflect.String:
+		sb.WriteString(strconv.Quote(val.String()))
+
+	default:
+		fmt.Fprintf(sb, "%v", val.Interface())
+	}
+}
+
+// enterStructKeyAncestor records the pointer, map, or slice `val` as an
+// ancestor of the values walked beneath it, returning a func that removes it
+// again.  If `val` is already an ancestor, it writes a back-reference instead
+// and returns false.
+func enterStructKeyAncestor(sb *strings.Builder, val reflect.Value, ancestors map[visitedPointer]int) (leave func(), ok bool) {
+	key := visitedPointer{val.Pointer(), val.Type()}
+	if depth, exists := ancestors[key]; exists {
+		fmt.Fprintf(sb, "^%d", len(ancestors)-depth)
+		return nil, false
+	}
+	ancestors[key] = len(ancestors)
+	return func() { delete(ancestors, key) }, true
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// marshalStructKey returns the encoding.TextMarshaler or json.Marshaler form of
+// the struct `val`, if it implements either.
+func marshalStructKey(val reflect.Value) (string, bool) {
+	if !val.CanInterface() {
+		return "", false
+	}
+	ptr := reflect.New(val.Type())
+	ptr.Elem().Set(val)
+
+	switch {
+	case ptr.Type().Implements(textMarshalerType):
+		bs, err := ptr.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(bs), err == nil
+	case ptr.Type().Implements(jsonMarshalerType):
+		bs, err := ptr.Interface().(json.Marshaler).MarshalJSON()
+		return string(bs), err == nil
+	}
+	return "", false
+}
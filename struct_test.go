@@ -0,0 +1,95 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/brynbellomy/go-utils"
+)
+
+func TestStructKey(t *testing.T) {
+	type inner struct {
+		Tags  []string
+		Attrs map[string]int
+	}
+	type outer struct {
+		Name   string
+		Count  int
+		Inner  inner
+		Ptr    *inner
+		hidden string
+	}
+
+	a := outer{
+		Name:   "a",
+		Count:  1,
+		Inner:  inner{Tags: []string{"x", "y"}, Attrs: map[string]int{"k1": 1, "k2": 2, "k3": 3}},
+		Ptr:    &inner{Tags: []string{"z"}},
+		hidden: "ignored",
+	}
+	b := outer{
+		Name:   "a",
+		Count:  1,
+		Inner:  inner{Tags: []string{"x", "y"}, Attrs: map[string]int{"k3": 3, "k2": 2, "k1": 1}},
+		Ptr:    &inner{Tags: []string{"z"}},
+		hidden: "also ignored",
+	}
+	require.Equal(t, utils.StructKey(a), utils.StructKey(b))
+	require.Equal(t, utils.StructKey(a), utils.StructKey(&b))
+
+	c := b
+	c.Inner.Tags = []string{"y", "x"}
+	require.NotEqual(t, utils.StructKey(a), utils.StructKey(c))
+
+	d := b
+	d.Ptr = &inner{Tags: []string{"zz"}}
+	require.NotEqual(t, utils.StructKey(a), utils.StructKey(d))
+
+	e := b
+	e.Inner.Attrs = map[string]int{"k1": 1, "k2": 2, "k3": 4}
+	require.NotEqual(t, utils.StructKey(a), utils.StructKey(e))
+
+	// Strings are quoted so that separators in values can't cause collisions
+	type pair struct{ A, B string }
+	require.NotEqual(t, utils.StructKey(pair{"x,B:y", ""}), utils.StructKey(pair{"x", "y"}))
+
+	t.Run("structs with only unexported fields use their marshaled form", func(t *testing.T) {
+		type event struct{ At time.Time }
+		require.NotEqual(t, utils.StructKey(event{At: time.Unix(0, 0)}), utils.StructKey(event{At: time.Unix(1000, 0)}))
+		require.Equal(t, utils.StructKey(event{At: time.Unix(1000, 0)}), utils.StructKey(event{At: time.Unix(1000, 0)}))
+	})
+
+	t.Run("interface values include their dynamic type", func(t *testing.T) {
+		type box struct{ V any }
+		require.NotEqual(t, utils.StructKey(box{V: 1}), utils.StructKey(box{V: 1.0}))
+		require.NotEqual(t, utils.StructKey(box{V: "1"}), utils.StructKey(box{V: 1}))
+		require.Equal(t, utils.StructKey(box{V: 1}), utils.StructKey(box{V: 1}))
+	})
+	t.Run("cycles", func(t *testing.T) {
+		type node struct {
+			Name string
+			Next *node
+		}
+		a := &node{Name: "a"}
+		a.Next = a
+		b := &node{Name: "a"}
+		b.Next = b
+		require.Equal(t, `{Name:"a",Next:^1}`, utils.StructKey(a))
+		require.Equal(t, utils.StructKey(a), utils.StructKey(b))
+
+		c := &node{Name: "c", Next: &node{Name: "a"}}
+		c.Next.Next = c
+		require.NotEqual(t, utils.StructKey(a), utils.StructKey(c))
+
+		m := map[string]any{"k": 1}
+		m["self"] = m
+		require.Contains(t, utils.StructKey(m), "^1")
+
+		// Shared (acyclic) pointers are walked in full each time
+		shared := &node{Name: "shared"}
+		type pair struct{ A, B *node }
+		require.Equal(t, utils.StructKey(pair{shared, shared}), utils.StructKey(pair{&node{Name: "shared"}, &node{Name: "shared"}}))
+	})
+}